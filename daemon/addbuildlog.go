@@ -0,0 +1,41 @@
+package daemon
+
+import (
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// AddBuildLog uploads the build log read from log for the given derivation
+// to the daemon's store, where it is shown by "nix log".
+// The log is streamed without being buffered.
+func (c *Conn) AddBuildLog(drvPath string, log io.Reader) error {
+	if err := c.requireMinor(32); err != nil {
+		return err
+	}
+
+	if err := c.writeNix(opcode.AddBuildLog); err != nil {
+		return fmt.Errorf("write operation to connection: %w", err)
+	}
+	// The daemon expects the derivation path without the store directory.
+	if err := c.writeNix(pseudo.String(path.Base(drvPath))); err != nil {
+		return fmt.Errorf("write derivation path to connection: %w", err)
+	}
+
+	if err := c.writeFramed(func(w io.Writer) error {
+		_, err := io.Copy(w, log)
+		return err
+	}); err != nil {
+		return fmt.Errorf("stream log: %w", err)
+	}
+
+	// The daemon always answers with 1 on success.
+	if _, err := c.readNixInt(); err != nil {
+		return fmt.Errorf("read result: %w", err)
+	}
+
+	return nil
+}
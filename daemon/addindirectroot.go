@@ -0,0 +1,34 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/msanft/proton/internal/protocol/operation"
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// AddIndirectRoot registers the given symlink, which points into the store, as a
+// garbage collector root. The store path the symlink points to is protected from
+// garbage collection until the symlink is removed, independent of this connection.
+// link must be an absolute path.
+func (c *Conn) AddIndirectRoot(link string) error {
+	l := pseudo.String(link)
+	if err := c.writeNix(operation.NewOperation(
+		opcode.AddIndirectRoot,
+		&l,
+	)); err != nil {
+		return fmt.Errorf("write link to connection: %w", err)
+	}
+
+	if err := c.ParseStderr(); err != nil {
+		return fmt.Errorf("receive stderr: %w", err)
+	}
+
+	// The daemon always answers with 1 on success.
+	if _, err := c.readNixInt(); err != nil {
+		return fmt.Errorf("read result: %w", err)
+	}
+
+	return nil
+}
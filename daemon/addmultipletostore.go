@@ -0,0 +1,77 @@
+package daemon
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/msanft/proton/internal/primitive"
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// AddMultipleToStore imports many store paths into the daemon's store at once,
+// which is a lot faster than calling AddToStoreNar for each of them.
+//
+// The daemon expects the number of paths up front, so count must be the number
+// of paths yielded by paths. paths is an iterator that yields the information
+// of each path together with its NAR, which is streamed to the daemon before
+// yield returns, so the NAR can be closed afterwards and only one needs to be
+// open at a time. If yield returns false, paths must stop.
+// The flags are applied to all paths, see AddToStoreNar.
+func (c *Conn) AddMultipleToStore(
+	count uint64, paths func(yield func(info ValidPathInfo, nar io.Reader) bool),
+	repair bool, dontCheckSigs bool,
+) error {
+	if err := c.requireMinor(32); err != nil {
+		return err
+	}
+
+	if err := c.writeNix(opcode.AddMultipleToStore); err != nil {
+		return fmt.Errorf("write operation to connection: %w", err)
+	}
+	if err := c.writeNix(pseudo.Bool(repair)); err != nil {
+		return fmt.Errorf("write repair flag to connection: %w", err)
+	}
+	if err := c.writeNix(pseudo.Bool(dontCheckSigs)); err != nil {
+		return fmt.Errorf("write dont-check-sigs flag to connection: %w", err)
+	}
+
+	if err := c.writeFramed(func(w io.Writer) error {
+		if err := writeNixTo(w, primitive.NewInt(count)); err != nil {
+			return fmt.Errorf("writing number of paths: %w", err)
+		}
+
+		var n uint64
+		var err error
+		paths(func(info ValidPathInfo, nar io.Reader) bool {
+			if err != nil {
+				return false
+			}
+			if n == count {
+				err = fmt.Errorf("more than %d paths were yielded", count)
+				return false
+			}
+			n++
+			if err = writeValidPathInfo(w, info); err != nil {
+				err = fmt.Errorf("writing path info of %s: %w", info.Path, err)
+				return false
+			}
+			if _, err = io.Copy(w, nar); err != nil {
+				err = fmt.Errorf("writing NAR of %s: %w", info.Path, err)
+				return false
+			}
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		if n != count {
+			return fmt.Errorf("%d paths were yielded, but %d were announced", n, count)
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("stream paths: %w", err)
+	}
+
+	return nil
+}
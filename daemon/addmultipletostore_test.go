@@ -0,0 +1,56 @@
+package daemon
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddMultipleToStore(t *testing.T) {
+	infos := []ValidPathInfo{
+		{Path: "/nix/store/00000000000000000000000000000000-foo", NarHash: "sha256:foo", NarSize: 3},
+		{Path: "/nix/store/00000000000000000000000000000000-bar", NarHash: "sha256:bar", NarSize: 5},
+	}
+	nars := []string{"foo", "barba"}
+	paths := func(yield func(ValidPathInfo, io.Reader) bool) {
+		for i, info := range infos {
+			if !yield(info, strings.NewReader(nars[i])) {
+				return
+			}
+		}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		require := require.New(t)
+		assert := assert.New(t)
+
+		c, sent, _ := testConn(32, last)
+		require.NoError(c.AddMultipleToStore(2, paths, false, true))
+
+		pathInfo := func(info ValidPathInfo) []byte {
+			return cat(
+				str(info.Path), str(""), str(info.NarHash), strs(), u64(0),
+				u64(info.NarSize), u64(0), strs(), str(""),
+			)
+		}
+		// The NARs are part of the framed stream, so they aren't padded.
+		stream := cat(u64(2), pathInfo(infos[0]), []byte("foo"), pathInfo(infos[1]), []byte("barba"))
+		assert.Equal(cat(
+			u64(uint64(opcode.AddMultipleToStore)), u64(0), u64(1),
+			u64(uint64(len(stream))), stream,
+			u64(0),
+		), sent.Bytes())
+	})
+
+	t.Run("count mismatch", func(t *testing.T) {
+		c, _, _ := testConn(32, last)
+		assert.Error(t, c.AddMultipleToStore(3, paths, false, false))
+
+		c, _, _ = testConn(32, last)
+		assert.Error(t, c.AddMultipleToStore(1, paths, false, false))
+	})
+}
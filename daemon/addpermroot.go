@@ -0,0 +1,38 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/msanft/proton/internal/protocol/operation"
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// AddPermRoot makes the daemon create a symlink at gcRoot pointing to the given
+// store path, and registers it as a garbage collector root. The store path stays
+// protected until the symlink is removed, independent of this connection.
+// It returns the absolute path of the created root.
+// This requires the connection to be trusted by the daemon.
+func (c *Conn) AddPermRoot(path string, gcRoot string) (string, error) {
+	p := pseudo.String(path)
+	if err := c.writeNix(operation.NewOperation(
+		opcode.AddPermRoot,
+		&p,
+	)); err != nil {
+		return "", fmt.Errorf("write store path to connection: %w", err)
+	}
+	if err := c.writeNix(pseudo.String(gcRoot)); err != nil {
+		return "", fmt.Errorf("write root to connection: %w", err)
+	}
+
+	if err := c.ParseStderr(); err != nil {
+		return "", fmt.Errorf("receive stderr: %w", err)
+	}
+
+	root, err := c.readNixString()
+	if err != nil {
+		return "", fmt.Errorf("read root: %w", err)
+	}
+
+	return string(root), nil
+}
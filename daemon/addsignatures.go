@@ -0,0 +1,63 @@
+package daemon
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/msanft/proton/internal/protocol/operation"
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// AddSignatures attaches the given signatures to the store path in the daemon's store.
+// Signatures have the format "keyname:base64sig", e.g. "cache.example.org-1:...".
+// They are validated before anything is sent to the daemon.
+func (c *Conn) AddSignatures(path string, sigs []string) error {
+	for _, sig := range sigs {
+		if err := validateSignature(sig); err != nil {
+			return fmt.Errorf("invalid signature %q: %w", sig, err)
+		}
+	}
+
+	p := pseudo.String(path)
+	if err := c.writeNix(operation.NewOperation(
+		opcode.AddSignatures,
+		&p,
+	)); err != nil {
+		return fmt.Errorf("write store path to connection: %w", err)
+	}
+	if err := c.writeNix(pseudo.StringList(sigs)); err != nil {
+		return fmt.Errorf("write signatures to connection: %w", err)
+	}
+
+	if err := c.ParseStderr(); err != nil {
+		return fmt.Errorf("receive stderr: %w", err)
+	}
+
+	// The daemon always answers with 1 on success.
+	if _, err := c.readNixInt(); err != nil {
+		return fmt.Errorf("read result: %w", err)
+	}
+
+	return nil
+}
+
+// validateSignature checks that the signature has the format "keyname:base64sig".
+func validateSignature(sig string) error {
+	name, encoded, found := strings.Cut(sig, ":")
+	if !found {
+		return errors.New("missing separator between key name and signature")
+	}
+	if name == "" {
+		return errors.New("empty key name")
+	}
+	if encoded == "" {
+		return errors.New("empty signature")
+	}
+	if _, err := base64.StdEncoding.DecodeString(encoded); err != nil {
+		return fmt.Errorf("decoding signature: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSignature(t *testing.T) {
+	testCases := map[string]struct {
+		sig     string
+		wantErr bool
+	}{
+		"valid": {
+			sig: "cache.nixos.org-1:PkaD0sZzBl2LfZ/eAqSAq6HdZqfvNHMR0T1avbQ9kDhMu0BXDnXS9PqEOgTsfA0VqFMo0l0rPTvxHNh2SRJvDQ==",
+		},
+		"missing separator": {
+			sig:     "cache.nixos.org-1",
+			wantErr: true,
+		},
+		"empty key name": {
+			sig:     ":PkaD0sZzBl2LfZ/eAqSAq6HdZqfvNHMR0T1avbQ9kDhMu0BXDnXS9PqEOgTsfA0VqFMo0l0rPTvxHNh2SRJvDQ==",
+			wantErr: true,
+		},
+		"empty signature": {
+			sig:     "cache.nixos.org-1:",
+			wantErr: true,
+		},
+		"invalid base64": {
+			sig:     "cache.nixos.org-1:not base64!",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := validateSignature(tc.sig)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/msanft/proton/internal/protocol/operation"
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// AddTempRoot protects the given store path from garbage collection for as long
+// as this connection is open. Once the connection is closed, the root is gone.
+//
+// Registering the root before checking the path's validity ensures that the path
+// isn't deleted between the check and its use.
+func (c *Conn) AddTempRoot(path string) error {
+	p := pseudo.String(path)
+	if err := c.writeNix(operation.NewOperation(
+		opcode.AddTempRoot,
+		&p,
+	)); err != nil {
+		return fmt.Errorf("write store path to connection: %w", err)
+	}
+
+	if err := c.ParseStderr(); err != nil {
+		return fmt.Errorf("receive stderr: %w", err)
+	}
+
+	// The daemon always answers with 1 on success.
+	if _, err := c.readNixInt(); err != nil {
+		return fmt.Errorf("read result: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// AddTextToStore adds a text file with the given contents to the daemon's store.
+// Unlike other content-addressed paths, text files may refer to other store paths,
+// which must be given as references. This is how .drv files are added.
+//
+// Daemons older than protocol version 1.25 don't support AddToStore with the text
+// method, so the legacy operation for adding text files is used with them.
+func (c *Conn) AddTextToStore(name string, text string, references []string, repair bool) (ValidPathInfo, error) {
+	if c.version.Minor() >= 25 {
+		return c.AddToStore(name, ContentAddressText, references, repair, strings.NewReader(text))
+	}
+
+	// The legacy operation has no repair flag.
+	if repair {
+		return ValidPathInfo{}, fmt.Errorf("%w: repairing text files requires protocol version v1.25, but %s was negotiated",
+			errors.ErrUnsupported, c.version)
+	}
+
+	if err := c.writeNix(opcode.AddTextToStore); err != nil {
+		return ValidPathInfo{}, fmt.Errorf("write operation to connection: %w", err)
+	}
+	if err := c.writeNix(pseudo.String(name)); err != nil {
+		return ValidPathInfo{}, fmt.Errorf("write name to connection: %w", err)
+	}
+	if err := c.writeNix(pseudo.String(text)); err != nil {
+		return ValidPathInfo{}, fmt.Errorf("write text to connection: %w", err)
+	}
+	if err := c.writeNix(pseudo.StringList(references)); err != nil {
+		return ValidPathInfo{}, fmt.Errorf("write references to connection: %w", err)
+	}
+
+	if err := c.ParseStderr(); err != nil {
+		return ValidPathInfo{}, fmt.Errorf("receive stderr: %w", err)
+	}
+
+	path, err := c.readNixString()
+	if err != nil {
+		return ValidPathInfo{}, fmt.Errorf("read store path: %w", err)
+	}
+
+	// The legacy operation only returns the path, so query the rest.
+	info, valid, err := c.QueryPathInfo(string(path))
+	if err != nil {
+		return ValidPathInfo{}, fmt.Errorf("query path info of %s: %w", path, err)
+	}
+	if !valid {
+		return ValidPathInfo{}, fmt.Errorf("added path %s is not valid", path)
+	}
+
+	return info, nil
+}
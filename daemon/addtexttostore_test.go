@@ -0,0 +1,59 @@
+package daemon
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddTextToStore(t *testing.T) {
+	const (
+		path = "/nix/store/00000000000000000000000000000000-foo.drv"
+		ref  = "/nix/store/00000000000000000000000000000000-bar"
+	)
+	pathInfo := cat(str(""), str("sha256:foo"), strs(ref), u64(1700000000), u64(128), u64(1), strs(), str("text:sha256:foo"))
+
+	t.Run("v1.24", func(t *testing.T) {
+		require := require.New(t)
+		assert := assert.New(t)
+
+		c, sent, _ := testConn(24,
+			last, str(path),
+			last, u64(1), pathInfo,
+		)
+
+		info, err := c.AddTextToStore("foo.drv", "Derive()", []string{ref}, false)
+		require.NoError(err)
+		assert.Equal(path, info.Path)
+		assert.Equal([]string{ref}, info.References)
+		assert.Equal(cat(
+			u64(uint64(opcode.AddTextToStore)), str("foo.drv"), str("Derive()"), strs(ref),
+			u64(uint64(opcode.QueryPathInfo)), str(path),
+		), sent.Bytes())
+	})
+
+	t.Run("v1.24 repair", func(t *testing.T) {
+		c, sent, _ := testConn(24)
+		_, err := c.AddTextToStore("foo.drv", "Derive()", []string{ref}, true)
+		assert.ErrorIs(t, err, errors.ErrUnsupported)
+		assert.Empty(t, sent.Bytes())
+	})
+
+	t.Run("v1.25", func(t *testing.T) {
+		require := require.New(t)
+		assert := assert.New(t)
+
+		c, sent, _ := testConn(25, last, str(path), pathInfo)
+
+		info, err := c.AddTextToStore("foo.drv", "Derive()", []string{ref}, true)
+		require.NoError(err)
+		assert.Equal(path, info.Path)
+		assert.Equal(cat(
+			u64(uint64(opcode.AddToStore)), str("foo.drv"), str("text:sha256"), strs(ref), u64(1),
+			u64(8), []byte("Derive()"), u64(0),
+		), sent.Bytes())
+	})
+}
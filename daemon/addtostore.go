@@ -0,0 +1,127 @@
+package daemon
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/msanft/proton/internal/nar"
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// ContentAddressMethod determines how the data added by AddToStore is
+// interpreted and hashed, e.g. "fixed:r:sha256".
+type ContentAddressMethod string
+
+// Content-addressing methods understood by AddToStore.
+const (
+	// ContentAddressText addresses text files, like .drv files, that may refer to
+	// other store paths. The dump is the file's contents.
+	ContentAddressText ContentAddressMethod = "text:sha256"
+	// ContentAddressRecursive addresses arbitrary file system objects by the hash of
+	// their NAR serialization. The dump is the NAR.
+	ContentAddressRecursive ContentAddressMethod = "fixed:r:sha256"
+	// ContentAddressFlat addresses a regular file by the hash of its contents.
+	// The dump is the file's contents.
+	ContentAddressFlat ContentAddressMethod = "fixed:sha256"
+)
+
+// validate returns an error if the daemon doesn't understand the method.
+// The daemon rejects unknown methods before it reads the dump, which would
+// leave the stream in the connection, so they must not be sent.
+func (m ContentAddressMethod) validate() error {
+	var algo string
+	switch {
+	case m == ContentAddressText:
+		return nil
+	case strings.HasPrefix(string(m), "fixed:r:"):
+		algo = strings.TrimPrefix(string(m), "fixed:r:")
+	case strings.HasPrefix(string(m), "fixed:"):
+		algo = strings.TrimPrefix(string(m), "fixed:")
+	default:
+		return fmt.Errorf("unknown content-addressing method %q", m)
+	}
+	switch algo {
+	case "md5", "sha1", "sha256", "sha512":
+		return nil
+	default:
+		return fmt.Errorf("unknown hash algorithm in content-addressing method %q", m)
+	}
+}
+
+// AddToStore adds the data read from dump to the daemon's store as a content-addressed
+// store path with the given name, and returns the information about the new path.
+// caMethod is the content-addressing method, e.g. ContentAddressRecursive, which
+// determines how dump is interpreted. references are the store paths the new path
+// refers to. If repair is set, an existing but corrupted path is replaced.
+// Besides the predefined methods, "fixed:" and "fixed:r:" may be combined with
+// the hash algorithms md5, sha1, sha256 and sha512.
+func (c *Conn) AddToStore(name string, caMethod ContentAddressMethod, references []string, repair bool, dump io.Reader) (ValidPathInfo, error) {
+	if err := c.requireMinor(25); err != nil {
+		return ValidPathInfo{}, err
+	}
+	if err := caMethod.validate(); err != nil {
+		return ValidPathInfo{}, err
+	}
+
+	if err := c.writeNix(opcode.AddToStore); err != nil {
+		return ValidPathInfo{}, fmt.Errorf("write operation to connection: %w", err)
+	}
+	if err := c.writeNix(pseudo.String(name)); err != nil {
+		return ValidPathInfo{}, fmt.Errorf("write name to connection: %w", err)
+	}
+	if err := c.writeNix(pseudo.String(caMethod)); err != nil {
+		return ValidPathInfo{}, fmt.Errorf("write content-addressing method to connection: %w", err)
+	}
+	if err := c.writeNix(pseudo.StringList(references)); err != nil {
+		return ValidPathInfo{}, fmt.Errorf("write references to connection: %w", err)
+	}
+	if err := c.writeNix(pseudo.Bool(repair)); err != nil {
+		return ValidPathInfo{}, fmt.Errorf("write repair flag to connection: %w", err)
+	}
+
+	if err := c.writeFramed(func(w io.Writer) error {
+		_, err := io.Copy(w, dump)
+		return err
+	}); err != nil {
+		return ValidPathInfo{}, fmt.Errorf("stream dump: %w", err)
+	}
+
+	path, err := c.readNixString()
+	if err != nil {
+		return ValidPathInfo{}, fmt.Errorf("read store path: %w", err)
+	}
+	info, err := c.readUnkeyedValidPathInfo(string(path))
+	if err != nil {
+		return ValidPathInfo{}, fmt.Errorf("read path info: %w", err)
+	}
+
+	return info, nil
+}
+
+// AddPathToStore adds the file system object at the local path, e.g. a directory,
+// to the daemon's store, like builtins.path does. It is serialized as a NAR while
+// being sent, and the resulting store path is addressed by the NAR's hash.
+func (c *Conn) AddPathToStore(name string, path string, repair bool) (ValidPathInfo, error) {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(nar.Dump(pw, path))
+	}()
+	defer pr.Close()
+
+	return c.AddToStore(name, ContentAddressRecursive, nil, repair, pr)
+}
+
+// AddFileToStore adds the contents of the local regular file at path to the daemon's
+// store, addressed by the hash of the contents. The resulting store path isn't executable.
+func (c *Conn) AddFileToStore(name string, path string, repair bool) (ValidPathInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return ValidPathInfo{}, fmt.Errorf("open file: %w", err)
+	}
+	defer f.Close()
+
+	return c.AddToStore(name, ContentAddressFlat, nil, repair, f)
+}
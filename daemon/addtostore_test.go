@@ -0,0 +1,43 @@
+package daemon
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddToStore(t *testing.T) {
+	const path = "/nix/store/00000000000000000000000000000000-foo"
+
+	t.Run("success", func(t *testing.T) {
+		require := require.New(t)
+		assert := assert.New(t)
+
+		c, sent, _ := testConn(25,
+			last,
+			str(path),
+			str(""), str("sha256:foo"), strs(), u64(1700000000), u64(3), u64(1), strs(), str("fixed:sha256:foo"),
+		)
+
+		info, err := c.AddToStore("foo", "fixed:sha512", nil, false, strings.NewReader("foo"))
+		require.NoError(err)
+		assert.Equal(path, info.Path)
+		assert.Equal("fixed:sha256:foo", info.ContentAddress)
+		assert.Equal(cat(
+			u64(uint64(opcode.AddToStore)), str("foo"), str("fixed:sha512"), strs(), u64(0),
+			u64(3), []byte("foo"), u64(0),
+		), sent.Bytes())
+	})
+
+	for _, method := range []ContentAddressMethod{"", "fixed:sha256:r", "fixed:r:blake3", "text:sha1", "nar:sha256"} {
+		t.Run("invalid method "+string(method), func(t *testing.T) {
+			c, sent, _ := testConn(25)
+			_, err := c.AddToStore("foo", method, nil, false, strings.NewReader("foo"))
+			assert.Error(t, err)
+			assert.Empty(t, sent.Bytes())
+		})
+	}
+}
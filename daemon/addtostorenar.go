@@ -0,0 +1,46 @@
+package daemon
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// AddToStoreNar imports the NAR read from nar into the daemon's store as the
+// store path described by info. The NAR is streamed without being buffered.
+// If repair is set, an existing but corrupted path is replaced.
+// If dontCheckSigs is set, the daemon doesn't require the path to be signed by a
+// trusted key, which requires the connection to be trusted.
+func (c *Conn) AddToStoreNar(info ValidPathInfo, nar io.Reader, repair bool, dontCheckSigs bool) error {
+	if err := c.writeNix(opcode.AddToStoreNar); err != nil {
+		return fmt.Errorf("write operation to connection: %w", err)
+	}
+	if err := writeValidPathInfo(c.w, info); err != nil {
+		return fmt.Errorf("write path info to connection: %w", err)
+	}
+	if err := c.writeNix(pseudo.Bool(repair)); err != nil {
+		return fmt.Errorf("write repair flag to connection: %w", err)
+	}
+	if err := c.writeNix(pseudo.Bool(dontCheckSigs)); err != nil {
+		return fmt.Errorf("write dont-check-sigs flag to connection: %w", err)
+	}
+
+	// Older daemons request the NAR piece by piece over the stderr channel.
+	if c.version.Minor() < 23 {
+		if err := c.parseStderr(nar); err != nil {
+			return fmt.Errorf("receive stderr: %w", err)
+		}
+		return nil
+	}
+
+	if err := c.writeFramed(func(w io.Writer) error {
+		_, err := io.Copy(w, nar)
+		return err
+	}); err != nil {
+		return fmt.Errorf("stream NAR: %w", err)
+	}
+
+	return nil
+}
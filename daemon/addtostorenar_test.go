@@ -0,0 +1,57 @@
+package daemon
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/msanft/proton/internal/protocol/stderr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddToStoreNar(t *testing.T) {
+	info := ValidPathInfo{
+		Path:    "/nix/store/00000000000000000000000000000000-foo",
+		NarHash: "sha256:foo",
+		NarSize: 9,
+	}
+	request := cat(
+		u64(uint64(opcode.AddToStoreNar)),
+		str(info.Path), str(""), str(info.NarHash), strs(), u64(0), u64(9), u64(0), strs(), str(""),
+		u64(1), u64(0),
+	)
+	readRequest := func(n uint64) []byte {
+		return cat(u64(uint64(stderr.MarkerRead)), u64(n))
+	}
+
+	t.Run("v1.22", func(t *testing.T) {
+		require := require.New(t)
+		assert := assert.New(t)
+
+		c, sent, _ := testConn(22, readRequest(4), readRequest(8), last)
+		require.NoError(c.AddToStoreNar(info, strings.NewReader("foobarbaz"), true, false))
+		// The data is sent as padded byte buffers of at most the requested length.
+		assert.Equal(cat(request, str("foob"), str("arbaz")), sent.Bytes())
+	})
+
+	t.Run("v1.22 without NAR", func(t *testing.T) {
+		c, _, _ := testConn(22, readRequest(4), last)
+		assert.Error(t, c.AddToStoreNar(info, nil, true, false))
+	})
+
+	t.Run("v1.22 with exhausted NAR", func(t *testing.T) {
+		c, _, _ := testConn(22, readRequest(16), readRequest(8), last)
+		assert.ErrorIs(t, c.AddToStoreNar(info, strings.NewReader("foobarbaz"), true, false), io.EOF)
+	})
+
+	t.Run("v1.23", func(t *testing.T) {
+		require := require.New(t)
+		assert := assert.New(t)
+
+		c, sent, _ := testConn(23, last)
+		require.NoError(c.AddToStoreNar(info, strings.NewReader("foobarbaz"), true, false))
+		assert.Equal(cat(request, u64(9), []byte("foobarbaz"), u64(0)), sent.Bytes())
+	})
+}
@@ -0,0 +1,92 @@
+package daemon
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/msanft/proton/internal/primitive"
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// BasicDerivation is a derivation whose inputs are all opaque store paths,
+// i.e. one that doesn't depend on other derivations.
+type BasicDerivation struct {
+	// Outputs maps the output names to their description.
+	Outputs map[string]DerivationOutput
+	// InputSrcs are the store paths the build depends on.
+	InputSrcs []string
+	// Platform is the system type the derivation is built on, e.g. "x86_64-linux".
+	Platform string
+	// Builder is the program that performs the build.
+	Builder string
+	// Args are the arguments passed to the builder.
+	Args []string
+	// Env is the environment the builder is run in.
+	Env map[string]string
+}
+
+// DerivationOutput describes an output of a derivation.
+type DerivationOutput struct {
+	// Path is the store path of the output.
+	// It is empty if the path isn't known before the build.
+	Path string
+	// HashAlgo is the content-addressing method and hash algorithm of the output,
+	// e.g. "r:sha256". It is empty for input-addressed outputs.
+	HashAlgo string
+	// Hash is the base16-encoded expected hash of a fixed-output derivation's output.
+	Hash string
+}
+
+// writeBasicDerivation writes the given derivation to the connection.
+func (c *Conn) writeBasicDerivation(drv BasicDerivation) error {
+	outputNames := make([]string, 0, len(drv.Outputs))
+	for name := range drv.Outputs {
+		outputNames = append(outputNames, name)
+	}
+	sort.Strings(outputNames)
+
+	if err := c.writeNix(primitive.NewInt(uint64(len(outputNames)))); err != nil {
+		return fmt.Errorf("writing number of outputs: %w", err)
+	}
+	for _, name := range outputNames {
+		output := drv.Outputs[name]
+		for _, s := range []string{name, output.Path, output.HashAlgo, output.Hash} {
+			if err := c.writeNix(pseudo.String(s)); err != nil {
+				return fmt.Errorf("writing output %s: %w", name, err)
+			}
+		}
+	}
+
+	if err := c.writeNix(pseudo.StringList(drv.InputSrcs)); err != nil {
+		return fmt.Errorf("writing input sources: %w", err)
+	}
+	if err := c.writeNix(pseudo.String(drv.Platform)); err != nil {
+		return fmt.Errorf("writing platform: %w", err)
+	}
+	if err := c.writeNix(pseudo.String(drv.Builder)); err != nil {
+		return fmt.Errorf("writing builder: %w", err)
+	}
+	if err := c.writeNix(pseudo.StringList(drv.Args)); err != nil {
+		return fmt.Errorf("writing arguments: %w", err)
+	}
+
+	envNames := make([]string, 0, len(drv.Env))
+	for name := range drv.Env {
+		envNames = append(envNames, name)
+	}
+	sort.Strings(envNames)
+
+	if err := c.writeNix(primitive.NewInt(uint64(len(envNames)))); err != nil {
+		return fmt.Errorf("writing number of environment variables: %w", err)
+	}
+	for _, name := range envNames {
+		if err := c.writeNix(pseudo.String(name)); err != nil {
+			return fmt.Errorf("writing environment variable name: %w", err)
+		}
+		if err := c.writeNix(pseudo.String(drv.Env[name])); err != nil {
+			return fmt.Errorf("writing environment variable %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteBasicDerivation(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	drv := BasicDerivation{
+		Outputs: map[string]DerivationOutput{
+			"out": {Path: "/nix/store/00000000000000000000000000000000-foo"},
+			"dev": {Path: "/nix/store/00000000000000000000000000000000-foo-dev"},
+			"doc": {HashAlgo: "r:sha256", Hash: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		},
+		InputSrcs: []string{"/nix/store/00000000000000000000000000000000-builder.sh"},
+		Platform:  "x86_64-linux",
+		Builder:   "/bin/sh",
+		Args:      []string{"-e", "/nix/store/00000000000000000000000000000000-builder.sh"},
+		Env: map[string]string{
+			"out":    "/nix/store/00000000000000000000000000000000-foo",
+			"name":   "foo",
+			"system": "x86_64-linux",
+			"dev":    "/nix/store/00000000000000000000000000000000-foo-dev",
+		},
+	}
+	want := cat(
+		u64(3),
+		str("dev"), str("/nix/store/00000000000000000000000000000000-foo-dev"), str(""), str(""),
+		str("doc"), str(""), str("r:sha256"), str("e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"),
+		str("out"), str("/nix/store/00000000000000000000000000000000-foo"), str(""), str(""),
+		strs("/nix/store/00000000000000000000000000000000-builder.sh"),
+		str("x86_64-linux"),
+		str("/bin/sh"),
+		strs("-e", "/nix/store/00000000000000000000000000000000-builder.sh"),
+		u64(4),
+		str("dev"), str("/nix/store/00000000000000000000000000000000-foo-dev"),
+		str("name"), str("foo"),
+		str("out"), str("/nix/store/00000000000000000000000000000000-foo"),
+		str("system"), str("x86_64-linux"),
+	)
+
+	// Map iteration order is random, so a single run could pass by chance.
+	for i := 0; i < 10; i++ {
+		c, sent, _ := testConn(37)
+		require.NoError(c.writeBasicDerivation(drv))
+		assert.Equal(want, sent.Bytes())
+	}
+}
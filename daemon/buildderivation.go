@@ -0,0 +1,41 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/msanft/proton/internal/protocol/buildmode"
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/msanft/proton/internal/protocol/operation"
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// BuildDerivation builds the given derivation, which is sent inline, so that its
+// .drv file doesn't need to exist in the daemon's store. drvPath is the store path
+// the derivation would have, and is used to identify the build.
+// Failing builds don't cause an error, but are reported in the result.
+func (c *Conn) BuildDerivation(drvPath string, drv BasicDerivation, mode buildmode.BuildMode) (BuildResult, error) {
+	p := pseudo.String(drvPath)
+	if err := c.writeNix(operation.NewOperation(
+		opcode.BuildDerivation,
+		&p,
+	)); err != nil {
+		return BuildResult{}, fmt.Errorf("write derivation path to connection: %w", err)
+	}
+	if err := c.writeBasicDerivation(drv); err != nil {
+		return BuildResult{}, fmt.Errorf("write derivation to connection: %w", err)
+	}
+	if err := c.writeNix(mode); err != nil {
+		return BuildResult{}, fmt.Errorf("write build mode to connection: %w", err)
+	}
+
+	if err := c.ParseStderr(); err != nil {
+		return BuildResult{}, fmt.Errorf("receive stderr: %w", err)
+	}
+
+	res, err := c.readBuildResult()
+	if err != nil {
+		return BuildResult{}, fmt.Errorf("read build result: %w", err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,54 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/msanft/proton/internal/protocol/buildmode"
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/msanft/proton/internal/protocol/operation"
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// BuildMode determines how the daemon treats outputs that already exist.
+type BuildMode = buildmode.BuildMode
+
+const (
+	// BuildModeNormal only builds outputs that don't exist yet.
+	BuildModeNormal = buildmode.Normal
+	// BuildModeRepair rebuilds or re-substitutes outputs that are corrupted.
+	BuildModeRepair = buildmode.Repair
+	// BuildModeCheck rebuilds existing outputs and checks that they are reproducible.
+	BuildModeCheck = buildmode.Check
+)
+
+// BuildPaths builds or substitutes the given derived paths in the daemon's store.
+// If any of them can't be realised, the daemon's *Error is returned.
+// Daemons older than protocol version 1.30 can't build an opaque derivation path,
+// i.e. one without Outputs, so an error wrapping [errors.ErrUnsupported] is returned.
+func (c *Conn) BuildPaths(paths []DerivedPath, mode buildmode.BuildMode) error {
+	strs, err := c.derivedPathStrings(paths)
+	if err != nil {
+		return fmt.Errorf("encode derived paths: %w", err)
+	}
+	p := pseudo.StringList(strs)
+	if err := c.writeNix(operation.NewOperation(
+		opcode.BuildPaths,
+		&p,
+	)); err != nil {
+		return fmt.Errorf("write derived paths to connection: %w", err)
+	}
+	if err := c.writeNix(mode); err != nil {
+		return fmt.Errorf("write build mode to connection: %w", err)
+	}
+
+	if err := c.ParseStderr(); err != nil {
+		return fmt.Errorf("receive stderr: %w", err)
+	}
+
+	// The daemon always answers with 1 on success.
+	if _, err := c.readNixInt(); err != nil {
+		return fmt.Errorf("read result: %w", err)
+	}
+
+	return nil
+}
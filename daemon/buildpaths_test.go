@@ -0,0 +1,58 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildPaths(t *testing.T) {
+	paths := []DerivedPath{
+		{Path: "/nix/store/00000000000000000000000000000000-foo"},
+		{Path: "/nix/store/00000000000000000000000000000000-bar.drv", Outputs: []string{"out", "dev"}},
+		{Path: "/nix/store/00000000000000000000000000000000-baz.drv", Outputs: []string{AllOutputs}},
+	}
+
+	testCases := map[string]struct {
+		minor uint8
+		want  []byte
+	}{
+		"v1.37": {
+			minor: 37,
+			want: cat(
+				u64(uint64(opcode.BuildPaths)),
+				strs(
+					"/nix/store/00000000000000000000000000000000-foo",
+					"/nix/store/00000000000000000000000000000000-bar.drv!out,dev",
+					"/nix/store/00000000000000000000000000000000-baz.drv!*",
+				),
+				u64(uint64(BuildModeRepair)),
+			),
+		},
+		"v1.29": {
+			minor: 29,
+			want: cat(
+				u64(uint64(opcode.BuildPaths)),
+				strs(
+					"/nix/store/00000000000000000000000000000000-foo",
+					"/nix/store/00000000000000000000000000000000-bar.drv!out,dev",
+					"/nix/store/00000000000000000000000000000000-baz.drv",
+				),
+				u64(uint64(BuildModeRepair)),
+			),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			assert := assert.New(t)
+
+			c, sent, _ := testConn(tc.minor, last, u64(1))
+			require.NoError(c.BuildPaths(paths, BuildModeRepair))
+			assert.Equal(tc.want, sent.Bytes())
+		})
+	}
+}
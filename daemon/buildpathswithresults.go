@@ -0,0 +1,61 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/msanft/proton/internal/protocol/buildmode"
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/msanft/proton/internal/protocol/operation"
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// BuildPathsWithResults builds or substitutes the given derived paths in the daemon's store
+// and returns the result for each of them.
+// Unlike BuildPaths, failing builds don't cause an error, but are reported in the results.
+func (c *Conn) BuildPathsWithResults(paths []DerivedPath, mode buildmode.BuildMode) ([]KeyedBuildResult, error) {
+	if err := c.requireMinor(34); err != nil {
+		return nil, err
+	}
+
+	strs, err := c.derivedPathStrings(paths)
+	if err != nil {
+		return nil, fmt.Errorf("encode derived paths: %w", err)
+	}
+	p := pseudo.StringList(strs)
+	if err := c.writeNix(operation.NewOperation(
+		opcode.BuildPathsWithResults,
+		&p,
+	)); err != nil {
+		return nil, fmt.Errorf("write derived paths to connection: %w", err)
+	}
+	if err := c.writeNix(mode); err != nil {
+		return nil, fmt.Errorf("write build mode to connection: %w", err)
+	}
+
+	if err := c.ParseStderr(); err != nil {
+		return nil, fmt.Errorf("receive stderr: %w", err)
+	}
+
+	n, err := c.readNixInt()
+	if err != nil {
+		return nil, fmt.Errorf("read number of results: %w", err)
+	}
+
+	var results []KeyedBuildResult
+	for i := uint64(0); i < n.Value; i++ {
+		path, err := c.readNixString()
+		if err != nil {
+			return nil, fmt.Errorf("read derived path: %w", err)
+		}
+		res, err := c.readBuildResult()
+		if err != nil {
+			return nil, fmt.Errorf("read build result for %s: %w", path, err)
+		}
+		results = append(results, KeyedBuildResult{
+			Path:        parseDerivedPath(string(path)),
+			BuildResult: res,
+		})
+	}
+
+	return results, nil
+}
@@ -0,0 +1,169 @@
+package daemon
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/msanft/proton/internal/protocol/buildstatus"
+)
+
+// BuildStatus is the outcome of realising a derived path.
+type BuildStatus = buildstatus.BuildStatus
+
+// Outcomes of realising a derived path.
+const (
+	BuildStatusBuilt                  = buildstatus.Built
+	BuildStatusSubstituted            = buildstatus.Substituted
+	BuildStatusAlreadyValid           = buildstatus.AlreadyValid
+	BuildStatusPermanentFailure       = buildstatus.PermanentFailure
+	BuildStatusInputRejected          = buildstatus.InputRejected
+	BuildStatusOutputRejected         = buildstatus.OutputRejected
+	BuildStatusTransientFailure       = buildstatus.TransientFailure
+	BuildStatusCachedFailure          = buildstatus.CachedFailure
+	BuildStatusTimedOut               = buildstatus.TimedOut
+	BuildStatusMiscFailure            = buildstatus.MiscFailure
+	BuildStatusDependencyFailed       = buildstatus.DependencyFailed
+	BuildStatusLogLimitExceeded       = buildstatus.LogLimitExceeded
+	BuildStatusNotDeterministic       = buildstatus.NotDeterministic
+	BuildStatusResolvesToAlreadyValid = buildstatus.ResolvesToAlreadyValid
+	BuildStatusNoSubstituters         = buildstatus.NoSubstituters
+)
+
+// BuildResult is the result of realising a derived path.
+type BuildResult struct {
+	// Status is the outcome of the build.
+	Status BuildStatus
+	// ErrorMsg describes the failure, if the build failed.
+	ErrorMsg string
+	// TimesBuilt is how many times the derivation was built, which is
+	// more than once when checking for determinism.
+	TimesBuilt uint64
+	// IsNonDeterministic is set if repeated builds produced different outputs.
+	IsNonDeterministic bool
+	// StartTime is the time the build started.
+	StartTime time.Time
+	// StopTime is the time the build finished.
+	StopTime time.Time
+	// CPUUser is the user CPU time spent on the build.
+	// It is nil if unknown, or if the daemon is older than protocol version 1.37.
+	CPUUser *time.Duration
+	// CPUSystem is the system CPU time spent on the build.
+	// It is nil if unknown, or if the daemon is older than protocol version 1.37.
+	CPUSystem *time.Duration
+	// BuiltOutputs maps the names of the built outputs to their realisations.
+	BuiltOutputs map[string]Realisation
+}
+
+// Success returns whether the derived path was realised successfully.
+func (r BuildResult) Success() bool {
+	switch r.Status {
+	case BuildStatusBuilt, BuildStatusSubstituted, BuildStatusAlreadyValid, BuildStatusResolvesToAlreadyValid:
+		return true
+	default:
+		return false
+	}
+}
+
+// KeyedBuildResult is the result of realising the contained derived path.
+type KeyedBuildResult struct {
+	Path DerivedPath
+	BuildResult
+}
+
+// readBuildResult reads a build result from the connection.
+func (c *Conn) readBuildResult() (BuildResult, error) {
+	var res BuildResult
+
+	status, err := c.readNixInt()
+	if err != nil {
+		return BuildResult{}, fmt.Errorf("reading status: %w", err)
+	}
+	res.Status = BuildStatus(status.Value)
+
+	errorMsg, err := c.readNixString()
+	if err != nil {
+		return BuildResult{}, fmt.Errorf("reading error message: %w", err)
+	}
+	res.ErrorMsg = string(errorMsg)
+
+	if c.version.Minor() >= 29 {
+		timesBuilt, err := c.readNixInt()
+		if err != nil {
+			return BuildResult{}, fmt.Errorf("reading times built: %w", err)
+		}
+		res.TimesBuilt = timesBuilt.Value
+
+		nonDeterministic, err := c.readNixBool()
+		if err != nil {
+			return BuildResult{}, fmt.Errorf("reading non-determinism flag: %w", err)
+		}
+		res.IsNonDeterministic = bool(nonDeterministic)
+
+		startTime, err := c.readNixInt()
+		if err != nil {
+			return BuildResult{}, fmt.Errorf("reading start time: %w", err)
+		}
+		res.StartTime = time.Unix(int64(startTime.Value), 0)
+
+		stopTime, err := c.readNixInt()
+		if err != nil {
+			return BuildResult{}, fmt.Errorf("reading stop time: %w", err)
+		}
+		res.StopTime = time.Unix(int64(stopTime.Value), 0)
+	}
+
+	if c.version.Minor() >= 37 {
+		if res.CPUUser, err = c.readOptionalMicroseconds(); err != nil {
+			return BuildResult{}, fmt.Errorf("reading user CPU time: %w", err)
+		}
+		if res.CPUSystem, err = c.readOptionalMicroseconds(); err != nil {
+			return BuildResult{}, fmt.Errorf("reading system CPU time: %w", err)
+		}
+	}
+
+	if c.version.Minor() >= 28 {
+		n, err := c.readNixInt()
+		if err != nil {
+			return BuildResult{}, fmt.Errorf("reading number of built outputs: %w", err)
+		}
+		res.BuiltOutputs = make(map[string]Realisation)
+		for i := uint64(0); i < n.Value; i++ {
+			rawID, err := c.readNixString()
+			if err != nil {
+				return BuildResult{}, fmt.Errorf("reading built output ID: %w", err)
+			}
+			id, err := ParseDrvOutput(string(rawID))
+			if err != nil {
+				return BuildResult{}, fmt.Errorf("parsing built output ID: %w", err)
+			}
+			realisation, err := c.readRealisation()
+			if err != nil {
+				return BuildResult{}, fmt.Errorf("reading built output %s: %w", id, err)
+			}
+			res.BuiltOutputs[id.OutputName] = realisation
+		}
+	}
+
+	return res, nil
+}
+
+// readOptionalMicroseconds reads an optional duration in microseconds from the connection.
+func (c *Conn) readOptionalMicroseconds() (*time.Duration, error) {
+	tag, err := c.readNixInt()
+	if err != nil {
+		return nil, fmt.Errorf("reading tag: %w", err)
+	}
+	switch tag.Value {
+	case 0:
+		return nil, nil
+	case 1:
+		us, err := c.readNixInt()
+		if err != nil {
+			return nil, fmt.Errorf("reading value: %w", err)
+		}
+		d := time.Duration(int64(us.Value)) * time.Microsecond
+		return &d, nil
+	default:
+		return nil, fmt.Errorf("unexpected tag: %d", tag.Value)
+	}
+}
@@ -0,0 +1,99 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadBuildResult(t *testing.T) {
+	const (
+		drvHash     = "sha256:1b2m2y8asgtpgatdkjmz5dyrfhg8nnnhpzldf1vrcfz5mvgcnl0q"
+		realisation = `{"id":"` + drvHash + `!out","outPath":"00000000000000000000000000000000-foo",` +
+			`"signatures":[],"dependentRealisations":{}}`
+	)
+	builtOutputs := map[string]Realisation{
+		"out": {
+			ID:                    DrvOutput{DrvHash: drvHash, OutputName: "out"},
+			OutPath:               "00000000000000000000000000000000-foo",
+			Signatures:            []string{},
+			DependentRealisations: map[DrvOutput]string{},
+		},
+	}
+	cpuUser := 1500 * time.Millisecond
+
+	testCases := map[string]struct {
+		minor uint8
+		reply []byte
+		want  BuildResult
+	}{
+		"v1.27": {
+			minor: 27,
+			reply: cat(u64(uint64(BuildStatusPermanentFailure)), str("builder failed")),
+			want:  BuildResult{Status: BuildStatusPermanentFailure, ErrorMsg: "builder failed"},
+		},
+		"v1.29": {
+			minor: 29,
+			reply: cat(
+				u64(uint64(BuildStatusBuilt)), str(""),
+				u64(2), u64(1), u64(1700000000), u64(1700000060),
+				u64(1), str(drvHash+"!out"), str(realisation),
+			),
+			want: BuildResult{
+				Status:             BuildStatusBuilt,
+				TimesBuilt:         2,
+				IsNonDeterministic: true,
+				StartTime:          time.Unix(1700000000, 0),
+				StopTime:           time.Unix(1700000060, 0),
+				BuiltOutputs:       builtOutputs,
+			},
+		},
+		"v1.37": {
+			minor: 37,
+			reply: cat(
+				u64(uint64(BuildStatusBuilt)), str(""),
+				u64(1), u64(0), u64(1700000000), u64(1700000060),
+				u64(1), u64(1500000), // user CPU time
+				u64(0), // no system CPU time
+				u64(1), str(drvHash+"!out"), str(realisation),
+			),
+			want: BuildResult{
+				Status:       BuildStatusBuilt,
+				TimesBuilt:   1,
+				StartTime:    time.Unix(1700000000, 0),
+				StopTime:     time.Unix(1700000060, 0),
+				CPUUser:      &cpuUser,
+				BuiltOutputs: builtOutputs,
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			assert := assert.New(t)
+
+			// The trailing integer checks that exactly the result was consumed.
+			c, _, _ := testConn(tc.minor, tc.reply, u64(42))
+			res, err := c.readBuildResult()
+			require.NoError(err)
+			assert.Equal(tc.want, res)
+
+			next, err := c.readNixInt()
+			require.NoError(err)
+			assert.Equal(uint64(42), next.Value)
+		})
+	}
+
+	t.Run("invalid CPU time tag", func(t *testing.T) {
+		c, _, _ := testConn(37,
+			u64(uint64(BuildStatusBuilt)), str(""),
+			u64(1), u64(0), u64(0), u64(0),
+			u64(2),
+		)
+		_, err := c.readBuildResult()
+		assert.Error(t, err)
+	})
+}
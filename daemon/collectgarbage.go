@@ -0,0 +1,101 @@
+package daemon
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/msanft/proton/internal/primitive"
+	"github.com/msanft/proton/internal/protocol/gcaction"
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// GCAction determines what the garbage collector does.
+type GCAction = gcaction.GCAction
+
+const (
+	// GCReturnLive returns the paths reachable from the roots, without deleting anything.
+	GCReturnLive = gcaction.ReturnLive
+	// GCReturnDead returns the paths not reachable from the roots, without deleting anything.
+	GCReturnDead = gcaction.ReturnDead
+	// GCDeleteDead deletes the paths not reachable from the roots.
+	GCDeleteDead = gcaction.DeleteDead
+	// GCDeleteSpecific deletes the paths in GCOptions.PathsToDelete.
+	GCDeleteSpecific = gcaction.DeleteSpecific
+)
+
+// GCOptions are the options for a garbage collector run.
+type GCOptions struct {
+	// Action is what the garbage collector does.
+	Action GCAction
+	// PathsToDelete are the store paths to delete if Action is GCDeleteSpecific.
+	PathsToDelete []string
+	// IgnoreLiveness deletes PathsToDelete even if they are still reachable from a root.
+	IgnoreLiveness bool
+	// MaxFreed stops the garbage collector once it freed at least this many bytes.
+	// If it is zero, there is no limit.
+	MaxFreed uint64
+}
+
+// GCResults are the results of a garbage collector run.
+type GCResults struct {
+	// Paths are the store paths that were deleted, or that would be
+	// deleted or kept, depending on the action.
+	Paths []string
+	// BytesFreed is the number of bytes freed.
+	BytesFreed uint64
+}
+
+// CollectGarbage runs the daemon's garbage collector with the given options.
+func (c *Conn) CollectGarbage(opts GCOptions) (GCResults, error) {
+	maxFreed := opts.MaxFreed
+	if maxFreed == 0 {
+		maxFreed = math.MaxUint64
+	}
+
+	if err := c.writeNix(opcode.CollectGarbage); err != nil {
+		return GCResults{}, fmt.Errorf("write operation to connection: %w", err)
+	}
+	if err := c.writeNix(opts.Action); err != nil {
+		return GCResults{}, fmt.Errorf("write action to connection: %w", err)
+	}
+	if err := c.writeNix(pseudo.StringList(opts.PathsToDelete)); err != nil {
+		return GCResults{}, fmt.Errorf("write paths to delete to connection: %w", err)
+	}
+	if err := c.writeNix(pseudo.Bool(opts.IgnoreLiveness)); err != nil {
+		return GCResults{}, fmt.Errorf("write ignore-liveness flag to connection: %w", err)
+	}
+	if err := c.writeNix(primitive.NewInt(maxFreed)); err != nil {
+		return GCResults{}, fmt.Errorf("write max freed to connection: %w", err)
+	}
+	// Write the three obsolete fields
+	for i := 0; i < 3; i++ {
+		if err := c.writeNix(primitive.NewInt(0)); err != nil {
+			return GCResults{}, fmt.Errorf("write obsolete field to connection: %w", err)
+		}
+	}
+
+	if err := c.ParseStderr(); err != nil {
+		return GCResults{}, fmt.Errorf("receive stderr: %w", err)
+	}
+
+	var results GCResults
+	var err error
+
+	if results.Paths, err = c.readNixStringList(); err != nil {
+		return GCResults{}, fmt.Errorf("read paths: %w", err)
+	}
+
+	bytesFreed, err := c.readNixInt()
+	if err != nil {
+		return GCResults{}, fmt.Errorf("read bytes freed: %w", err)
+	}
+	results.BytesFreed = bytesFreed.Value
+
+	// Read the obsolete field
+	if _, err := c.readNixInt(); err != nil {
+		return GCResults{}, fmt.Errorf("read obsolete field: %w", err)
+	}
+
+	return results, nil
+}
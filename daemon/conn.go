@@ -1,6 +1,7 @@
 package daemon
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
 	"io"
@@ -17,13 +18,17 @@ type Conn struct {
 
 	stderr io.Writer
 
+	// version is the protocol version negotiated with the daemon.
+	version protocol.Version
+
 	// PeerVersion is the Nix version used by the other end of the connection.
 	PeerVersion string
 }
 
 // NewConn establishes a connection to a Nix daemon through the given connection.
 func NewConn(r io.Reader, w io.Writer, stderr io.Writer) (*Conn, error) {
-	c := &Conn{r: r, w: w, stderr: stderr}
+	// Replies are read in many small chunks, so buffer them.
+	c := &Conn{r: bufio.NewReader(r), w: w, stderr: stderr}
 	err := c.shakeHands()
 	if err != nil {
 		return nil, fmt.Errorf("performing handshake: %w", err)
@@ -31,6 +36,16 @@ func NewConn(r io.Reader, w io.Writer, stderr io.Writer) (*Conn, error) {
 	return c, nil
 }
 
+// requireMinor returns an error wrapping [errors.ErrUnsupported] if the protocol version
+// negotiated with the daemon is older than 1.minor.
+func (c *Conn) requireMinor(minor uint8) error {
+	if c.version.Minor() < minor {
+		return fmt.Errorf("%w: requires protocol version v%d.%d, but %s was negotiated",
+			errors.ErrUnsupported, protocol.Major, minor, c.version)
+	}
+	return nil
+}
+
 // nixMarshalable is an interface for types that can be marshaled into
 // the Nix wire format.
 type nixMarshalable interface {
@@ -40,12 +55,18 @@ type nixMarshalable interface {
 // writeNix marshals the given data to the Nix wire format
 // and writes it to the connection.
 func (c *Conn) writeNix(data nixMarshalable) error {
+	return writeNixTo(c.w, data)
+}
+
+// writeNixTo marshals the given data to the Nix wire format
+// and writes it to w.
+func writeNixTo(w io.Writer, data nixMarshalable) error {
 	b, err := data.MarshalNix()
 	if err != nil {
 		return fmt.Errorf("marshaling data: %w", err)
 	}
 	// fmt.Printf("Write %x\n", b)
-	if _, err = c.w.Write(b); err != nil {
+	if _, err = w.Write(b); err != nil {
 		return fmt.Errorf("writing data: %w", err)
 	}
 	return nil
@@ -62,7 +83,7 @@ type nixUnmarshalable interface {
 // Callers should rather use the type-specific implementations like ReadNixInt.
 func (c *Conn) readNNix(data nixUnmarshalable, n int) error {
 	buf := make([]byte, n)
-	_, err := c.r.Read(buf)
+	_, err := io.ReadFull(c.r, buf)
 	if err != nil {
 		return fmt.Errorf("reading data: %w", err)
 	}
@@ -91,13 +112,12 @@ func (c *Conn) readNixByteBuf() (primitive.ByteBuf, error) {
 
 	// Read the actual buffer
 	buf := make([]byte, l.Value)
-	if _, err = c.r.Read(buf); err != nil {
+	if _, err = io.ReadFull(c.r, buf); err != nil {
 		return primitive.ByteBuf{}, fmt.Errorf("reading buffer: %w", err)
 	}
 
 	// Discard the padding
-	pad := 8 - l.Value%8
-	if pad > 0 {
+	if pad := (8 - l.Value%8) % 8; pad > 0 {
 		if _, err := io.CopyN(io.Discard, c.r, int64(pad)); err != nil {
 			return primitive.ByteBuf{}, fmt.Errorf("discarding padding: %w", err)
 		}
@@ -106,6 +126,32 @@ func (c *Conn) readNixByteBuf() (primitive.ByteBuf, error) {
 	return primitive.ByteBuf{Len: l, Buf: buf}, nil
 }
 
+// readNixString reads a string from the connection.
+func (c *Conn) readNixString() (pseudo.String, error) {
+	b, err := c.readNixByteBuf()
+	if err != nil {
+		return "", err
+	}
+	return pseudo.String(b.Buf), nil
+}
+
+// readNixStringList reads a length-prefixed list of strings from the connection.
+func (c *Conn) readNixStringList() (pseudo.StringList, error) {
+	l, err := c.readNixInt()
+	if err != nil {
+		return nil, fmt.Errorf("reading list length: %w", err)
+	}
+	var list pseudo.StringList
+	for i := uint64(0); i < l.Value; i++ {
+		s, err := c.readNixString()
+		if err != nil {
+			return nil, fmt.Errorf("reading list element %d: %w", i, err)
+		}
+		list = append(list, string(s))
+	}
+	return list, nil
+}
+
 // readNixBool reads a boolean from the connection.
 func (c *Conn) readNixBool() (pseudo.Bool, error) {
 	var b pseudo.Bool
@@ -141,11 +187,10 @@ func (c *Conn) shakeHands() error {
 		return fmt.Errorf("reading server protocol version: %w", err)
 	}
 	// Check whether the server's protocol version is compatible with ours.
-	// TODO: Make this more flexible.
 	clientVersion := protocol.OwnVersion()
-	if serverVersion != clientVersion {
+	if serverVersion.Major() != clientVersion.Major() || serverVersion.Minor() < protocol.MinMinor {
 		return fmt.Errorf(
-			"protocol version mismatch: server is %s, client is %s",
+			"unsupported protocol version: server is %s, client is %s",
 			serverVersion, clientVersion)
 	}
 
@@ -154,6 +199,12 @@ func (c *Conn) shakeHands() error {
 		return fmt.Errorf("writing client protocol version: %w", err)
 	}
 
+	// From now on, both sides speak the older of the two versions.
+	c.version = clientVersion
+	if serverVersion.Minor() < clientVersion.Minor() {
+		c.version = serverVersion
+	}
+
 	// Write the now obsolete CPU Affinity..
 	if err := c.writeNix(primitive.NewInt(0)); err != nil {
 		return fmt.Errorf("writing CPU affinity: %w", err)
@@ -164,33 +215,32 @@ func (c *Conn) shakeHands() error {
 	}
 
 	// Read the server's Nix version
-	v, err := c.readNixByteBuf()
-	if err != nil {
-		return fmt.Errorf("reading server Nix version: %w", err)
+	if c.version.Minor() >= 33 {
+		v, err := c.readNixByteBuf()
+		if err != nil {
+			return fmt.Errorf("reading server Nix version: %w", err)
+		}
+		c.PeerVersion = string(v.Buf)
 	}
-	c.PeerVersion = string(v.Buf)
 
 	// Read whether we're trusted
-	trusted, err := c.readNixInt()
-	if err != nil {
-		return fmt.Errorf("reading trusted status: %w", err)
-	}
-	switch trusted.Value {
-	case 0: // Unset
-	case 1: // Trusted
-	case 2: // Untrusted
-		return errors.New("connection is untrusted")
-	default:
-		return fmt.Errorf("unexpected trusted status: %d", trusted.Value)
+	if c.version.Minor() >= 35 {
+		trusted, err := c.readNixInt()
+		if err != nil {
+			return fmt.Errorf("reading trusted status: %w", err)
+		}
+		switch trusted.Value {
+		case 0: // Unset
+		case 1: // Trusted
+		case 2: // Untrusted
+			return errors.New("connection is untrusted")
+		default:
+			return fmt.Errorf("unexpected trusted status: %d", trusted.Value)
+		}
 	}
 
-	// TODO: Properly read stderr
-	stderrMarker, err := c.readNixInt()
-	if err != nil {
-		return fmt.Errorf("reading stderr marker: %w", err)
-	}
-	if stderrMarker.Value != 0x616c7473 {
-		return fmt.Errorf("unexpected stderr marker: got %x, want 0x616c7473", stderrMarker.Value)
+	if err := c.ParseStderr(); err != nil {
+		return fmt.Errorf("receiving stderr: %w", err)
 	}
 
 	return nil
@@ -0,0 +1,51 @@
+package daemon
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/msanft/proton/internal/protocol"
+	"github.com/msanft/proton/internal/protocol/stderr"
+)
+
+// u64 encodes an integer in the Nix wire format.
+func u64(v uint64) []byte {
+	return binary.LittleEndian.AppendUint64(nil, v)
+}
+
+// str encodes a string in the Nix wire format.
+func str(s string) []byte {
+	b := append(u64(uint64(len(s))), s...)
+	return append(b, make([]byte, (8-len(s)%8)%8)...)
+}
+
+// strs encodes a list of strings in the Nix wire format.
+func strs(ss ...string) []byte {
+	b := u64(uint64(len(ss)))
+	for _, s := range ss {
+		b = append(b, str(s)...)
+	}
+	return b
+}
+
+// cat concatenates encoded values.
+func cat(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+// last is the marker ending the daemon's stderr messages.
+var last = u64(uint64(stderr.MarkerLast))
+
+// testConn returns a connection speaking protocol version 1.minor that reads
+// the given reply. It also returns the buffers receiving the data sent to the
+// daemon and the log messages.
+func testConn(minor uint8, reply ...[]byte) (c *Conn, sent, log *bytes.Buffer) {
+	sent, log = &bytes.Buffer{}, &bytes.Buffer{}
+	c = &Conn{
+		r:       bytes.NewReader(cat(reply...)),
+		w:       sent,
+		stderr:  log,
+		version: protocol.NewVersion(protocol.Major, minor),
+	}
+	return c, sent, log
+}
@@ -0,0 +1,78 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/msanft/proton/internal/protocol"
+)
+
+// AllOutputs can be used as the only output of a DerivedPath to select
+// all outputs of the derivation.
+const AllOutputs = "*"
+
+// DerivedPath is a store path that is either opaque, or refers to outputs
+// of a derivation that might need to be built first.
+type DerivedPath struct {
+	// Path is the store path. If Outputs is set, it is the store path of the derivation.
+	Path string
+	// Outputs are the names of the derivation outputs the path refers to.
+	// If it is empty, Path is opaque.
+	Outputs []string
+}
+
+// String returns the representation of the derived path used on the command line,
+// e.g. "/nix/store/...-foo.drv^out,dev".
+func (p DerivedPath) String() string {
+	return p.join("^")
+}
+
+// legacyString returns the representation of the derived path used by the daemon,
+// e.g. "/nix/store/...-foo.drv!out,dev". Nix calls it legacy, as it predates
+// the command line representation.
+func (p DerivedPath) legacyString() string {
+	return p.join("!")
+}
+
+// join joins the path and the outputs with the given separator.
+func (p DerivedPath) join(sep string) string {
+	if len(p.Outputs) == 0 {
+		return p.Path
+	}
+	return p.Path + sep + strings.Join(p.Outputs, ",")
+}
+
+// parseDerivedPath parses the representation of a derived path used by the daemon.
+func parseDerivedPath(s string) DerivedPath {
+	path, outputs, found := strings.Cut(s, "!")
+	if !found {
+		return DerivedPath{Path: s}
+	}
+	return DerivedPath{Path: path, Outputs: strings.Split(outputs, ",")}
+}
+
+// derivedPathStrings returns the representation of the given derived paths
+// that is understood by the other end of the connection.
+func (c *Conn) derivedPathStrings(paths []DerivedPath) ([]string, error) {
+	strs := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if c.version.Minor() >= 30 {
+			strs = append(strs, p.legacyString())
+			continue
+		}
+
+		// Older daemons only understand store paths with outputs, where a
+		// derivation without outputs selects all of its outputs.
+		switch {
+		case len(p.Outputs) == 1 && p.Outputs[0] == AllOutputs:
+			strs = append(strs, p.Path)
+		case len(p.Outputs) == 0 && strings.HasSuffix(p.Path, ".drv"):
+			return nil, fmt.Errorf("%w: referring to the derivation %s itself requires protocol version v%d.30, but %s was negotiated",
+				errors.ErrUnsupported, p.Path, protocol.Major, c.version)
+		default:
+			strs = append(strs, p.legacyString())
+		}
+	}
+	return strs, nil
+}
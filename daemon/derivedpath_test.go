@@ -0,0 +1,90 @@
+package daemon
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDerivedPathString(t *testing.T) {
+	assert := assert.New(t)
+
+	opaque := DerivedPath{Path: "/nix/store/00000000000000000000000000000000-foo"}
+	assert.Equal("/nix/store/00000000000000000000000000000000-foo", opaque.String())
+
+	built := DerivedPath{
+		Path:    "/nix/store/00000000000000000000000000000000-foo.drv",
+		Outputs: []string{"out", "dev"},
+	}
+	assert.Equal("/nix/store/00000000000000000000000000000000-foo.drv^out,dev", built.String())
+}
+
+func TestDerivedPathStrings(t *testing.T) {
+	opaque := DerivedPath{Path: "/nix/store/00000000000000000000000000000000-foo"}
+	opaqueDrv := DerivedPath{Path: "/nix/store/00000000000000000000000000000000-foo.drv"}
+	built := DerivedPath{
+		Path:    "/nix/store/00000000000000000000000000000000-foo.drv",
+		Outputs: []string{"out", "dev"},
+	}
+	all := DerivedPath{
+		Path:    "/nix/store/00000000000000000000000000000000-foo.drv",
+		Outputs: []string{AllOutputs},
+	}
+
+	t.Run("v1.30", func(t *testing.T) {
+		require := require.New(t)
+		assert := assert.New(t)
+
+		c, _, _ := testConn(30)
+		strs, err := c.derivedPathStrings([]DerivedPath{opaque, opaqueDrv, built, all})
+		require.NoError(err)
+		assert.Equal([]string{
+			"/nix/store/00000000000000000000000000000000-foo",
+			"/nix/store/00000000000000000000000000000000-foo.drv",
+			"/nix/store/00000000000000000000000000000000-foo.drv!out,dev",
+			"/nix/store/00000000000000000000000000000000-foo.drv!*",
+		}, strs)
+	})
+
+	t.Run("v1.29", func(t *testing.T) {
+		require := require.New(t)
+		assert := assert.New(t)
+
+		c, _, _ := testConn(29)
+		strs, err := c.derivedPathStrings([]DerivedPath{opaque, built, all})
+		require.NoError(err)
+		assert.Equal([]string{
+			"/nix/store/00000000000000000000000000000000-foo",
+			"/nix/store/00000000000000000000000000000000-foo.drv!out,dev",
+			"/nix/store/00000000000000000000000000000000-foo.drv",
+		}, strs)
+
+		_, err = c.derivedPathStrings([]DerivedPath{opaqueDrv})
+		assert.ErrorIs(err, errors.ErrUnsupported)
+	})
+}
+
+func TestParseDerivedPath(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(
+		DerivedPath{Path: "/nix/store/00000000000000000000000000000000-foo"},
+		parseDerivedPath("/nix/store/00000000000000000000000000000000-foo"),
+	)
+	assert.Equal(
+		DerivedPath{
+			Path:    "/nix/store/00000000000000000000000000000000-foo.drv",
+			Outputs: []string{"out", "dev"},
+		},
+		parseDerivedPath("/nix/store/00000000000000000000000000000000-foo.drv!out,dev"),
+	)
+	assert.Equal(
+		DerivedPath{
+			Path:    "/nix/store/00000000000000000000000000000000-foo.drv",
+			Outputs: []string{AllOutputs},
+		},
+		parseDerivedPath("/nix/store/00000000000000000000000000000000-foo.drv!*"),
+	)
+}
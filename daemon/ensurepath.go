@@ -0,0 +1,39 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/msanft/proton/internal/protocol/operation"
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// EnsurePath makes the given store path valid in the daemon's store, substituting
+// it if necessary. The progress of the substitution is written into the connection's
+// stderr writer.
+// If the path can't be made valid, a *PathNotValidError is returned.
+func (c *Conn) EnsurePath(path string) error {
+	p := pseudo.String(path)
+	if err := c.writeNix(operation.NewOperation(
+		opcode.EnsurePath,
+		&p,
+	)); err != nil {
+		return fmt.Errorf("write store path to connection: %w", err)
+	}
+
+	if err := c.ParseStderr(); err != nil {
+		var daemonErr *Error
+		if errors.As(err, &daemonErr) {
+			return &PathNotValidError{Path: path, Err: daemonErr}
+		}
+		return fmt.Errorf("receive stderr: %w", err)
+	}
+
+	// The daemon always answers with 1 on success.
+	if _, err := c.readNixInt(); err != nil {
+		return fmt.Errorf("read result: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/msanft/proton/internal/protocol/stderr"
+)
+
+// Error is an error reported by the daemon while processing an operation.
+// The connection stays usable after such an error.
+type Error struct {
+	// Level is the verbosity level the error was logged with.
+	Level uint64
+	// Name is the name of the error type, if any.
+	Name string
+	// Message is the error message.
+	Message string
+	// Traces are additional messages giving context to the error,
+	// from the innermost to the outermost.
+	Traces []string
+	// Status is the exit status associated with the error.
+	// It is only reported by daemons older than protocol version 1.26.
+	Status uint64
+}
+
+// newError converts an error received over the stderr format.
+func newError(e stderr.Error) *Error {
+	err := &Error{
+		Level:   e.Level.Value,
+		Name:    string(e.Name.Buf),
+		Message: string(e.Message.Buf),
+	}
+	for _, trace := range e.Traces {
+		err.Traces = append(err.Traces, string(trace.Message.Buf))
+	}
+	return err
+}
+
+// Error returns the error message.
+func (e *Error) Error() string {
+	return fmt.Sprintf("daemon error: %s", e.Message)
+}
+
+// PathNotValidError is returned if a store path can't be made valid,
+// e.g. because it can neither be substituted nor built.
+type PathNotValidError struct {
+	// Path is the store path that isn't valid.
+	Path string
+	// Err is the error reported by the daemon.
+	Err error
+}
+
+// Error returns the error message.
+func (e *PathNotValidError) Error() string {
+	return fmt.Sprintf("path %s can't be made valid: %s", e.Path, e.Err)
+}
+
+// Unwrap returns the error reported by the daemon.
+func (e *PathNotValidError) Unwrap() error {
+	return e.Err
+}
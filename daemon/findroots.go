@@ -0,0 +1,44 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+)
+
+// FindRoots returns the garbage collector roots known to the daemon, as a map
+// from the root to the store paths it keeps alive.
+//
+// Besides symlinks like profiles and result links, which keep a single store path
+// alive, roots include pseudo-roots that don't exist on the file system, e.g.
+// "{temp:1234}" for the temporary roots of a running process, or "{censored}" for
+// roots the connection may not see. Those can keep many store paths alive.
+func (c *Conn) FindRoots() (map[string][]string, error) {
+	if err := c.writeNix(opcode.FindRoots); err != nil {
+		return nil, fmt.Errorf("write operation to connection: %w", err)
+	}
+
+	if err := c.ParseStderr(); err != nil {
+		return nil, fmt.Errorf("receive stderr: %w", err)
+	}
+
+	n, err := c.readNixInt()
+	if err != nil {
+		return nil, fmt.Errorf("read number of roots: %w", err)
+	}
+
+	roots := make(map[string][]string)
+	for i := uint64(0); i < n.Value; i++ {
+		link, err := c.readNixString()
+		if err != nil {
+			return nil, fmt.Errorf("read root: %w", err)
+		}
+		path, err := c.readNixString()
+		if err != nil {
+			return nil, fmt.Errorf("read store path of root %s: %w", link, err)
+		}
+		roots[string(link)] = append(roots[string(link)], string(path))
+	}
+
+	return roots, nil
+}
@@ -0,0 +1,42 @@
+package daemon
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/msanft/proton/internal/protocol/framed"
+)
+
+// writeFramed sends the data written by write as a framed stream, while reading
+// the daemon's stderr messages, as the daemon may log while it consumes the stream.
+// It returns once the daemon finished processing the stream.
+func (c *Conn) writeFramed(write func(w io.Writer) error) error {
+	stderrErr := make(chan error, 1)
+	go func() {
+		stderrErr <- c.ParseStderr()
+	}()
+
+	// Buffer the data, so that small writes don't end up in frames of their own.
+	fw := framed.NewWriter(c.w)
+	bw := bufio.NewWriterSize(fw, 64<<10)
+
+	// The stream is ended even if write fails, so that the daemon
+	// stops waiting for data and reports the truncated stream.
+	var writeErr error
+	if err := write(bw); err != nil {
+		writeErr = fmt.Errorf("writing framed stream: %w", err)
+	}
+	if err := bw.Flush(); err != nil {
+		writeErr = errors.Join(writeErr, fmt.Errorf("flushing framed stream: %w", err))
+	}
+	if err := fw.Close(); err != nil {
+		writeErr = errors.Join(writeErr, fmt.Errorf("ending framed stream: %w", err))
+	}
+
+	if err := <-stderrErr; err != nil {
+		return errors.Join(writeErr, fmt.Errorf("receive stderr: %w", err))
+	}
+	return writeErr
+}
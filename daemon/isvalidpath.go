@@ -19,21 +19,13 @@ func (c *Conn) IsValidPath(path string) (bool, error) {
 		return false, fmt.Errorf("write store path to connection: %w", err)
 	}
 
-	rest, err := c.ParseStderr()
-	if err != nil {
+	if err := c.ParseStderr(); err != nil {
 		return false, fmt.Errorf("receive stderr: %w", err)
 	}
 
-	var validity pseudo.Bool
-	if rest == nil {
-		validity, err = c.readNixBool()
-		if err != nil {
-			return false, fmt.Errorf("read validity: %w", err)
-		}
-	} else {
-		if err := validity.UnmarshalNix(rest); err != nil {
-			return false, fmt.Errorf("unmarshal validity: %w", err)
-		}
+	validity, err := c.readNixBool()
+	if err != nil {
+		return false, fmt.Errorf("read validity: %w", err)
 	}
 
 	return bool(validity), nil
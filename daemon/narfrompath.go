@@ -0,0 +1,37 @@
+package daemon
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/msanft/proton/internal/nar"
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/msanft/proton/internal/protocol/operation"
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// NarFromPath writes the NAR serialization of the given store path into w.
+// The archive is streamed from the daemon without being buffered.
+// If writing into w fails, the rest of the archive is still read from the daemon
+// to keep the connection usable, and the write error is returned.
+func (c *Conn) NarFromPath(path string, w io.Writer) error {
+	p := pseudo.String(path)
+	if err := c.writeNix(operation.NewOperation(
+		opcode.NarFromPath,
+		&p,
+	)); err != nil {
+		return fmt.Errorf("write store path to connection: %w", err)
+	}
+
+	if err := c.ParseStderr(); err != nil {
+		return fmt.Errorf("receive stderr: %w", err)
+	}
+
+	// The daemon sends the NAR without a length prefix,
+	// so it needs to be parsed to know where it ends.
+	if _, err := nar.Copy(w, c.r); err != nil {
+		return fmt.Errorf("copy NAR: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+)
+
+// OptimiseStore replaces identical files in the daemon's store with hard links
+// to a single copy. The progress and the space saved by hard-linking are written
+// into the connection's stderr writer.
+func (c *Conn) OptimiseStore() error {
+	if err := c.writeNix(opcode.OptimiseStore); err != nil {
+		return fmt.Errorf("write operation to connection: %w", err)
+	}
+
+	if err := c.ParseStderr(); err != nil {
+		return fmt.Errorf("receive stderr: %w", err)
+	}
+
+	// The daemon always answers with 1 on success.
+	if _, err := c.readNixInt(); err != nil {
+		return fmt.Errorf("read result: %w", err)
+	}
+
+	return nil
+}
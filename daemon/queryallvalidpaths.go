@@ -0,0 +1,42 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+)
+
+// QueryAllValidPaths calls fn for every valid path in the daemon's store.
+// Paths are passed to fn as they are read from the connection, so the
+// whole store is never held in memory at once.
+//
+// If fn returns an error, the remaining paths are still consumed to keep
+// the connection usable, but fn isn't called anymore and its error is returned.
+func (c *Conn) QueryAllValidPaths(fn func(path string) error) error {
+	if err := c.writeNix(opcode.QueryAllValidPaths); err != nil {
+		return fmt.Errorf("write operation to connection: %w", err)
+	}
+
+	if err := c.ParseStderr(); err != nil {
+		return fmt.Errorf("receive stderr: %w", err)
+	}
+
+	n, err := c.readNixInt()
+	if err != nil {
+		return fmt.Errorf("read number of valid paths: %w", err)
+	}
+
+	var fnErr error
+	for i := uint64(0); i < n.Value; i++ {
+		path, err := c.readNixString()
+		if err != nil {
+			return fmt.Errorf("read valid path %d: %w", i, err)
+		}
+		if fnErr != nil {
+			continue
+		}
+		fnErr = fn(string(path))
+	}
+
+	return fnErr
+}
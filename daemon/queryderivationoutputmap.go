@@ -0,0 +1,57 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/msanft/proton/internal/protocol/operation"
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// QueryDerivationOutputMap returns a map from the output names of the given
+// derivation to their store paths.
+// The paths of content-addressed outputs aren't known before the derivation is
+// built, so they are nil until then.
+func (c *Conn) QueryDerivationOutputMap(drvPath string) (map[string]*string, error) {
+	if err := c.requireMinor(22); err != nil {
+		return nil, err
+	}
+
+	p := pseudo.String(drvPath)
+	if err := c.writeNix(operation.NewOperation(
+		opcode.QueryDerivationOutputMap,
+		&p,
+	)); err != nil {
+		return nil, fmt.Errorf("write derivation path to connection: %w", err)
+	}
+
+	if err := c.ParseStderr(); err != nil {
+		return nil, fmt.Errorf("receive stderr: %w", err)
+	}
+
+	n, err := c.readNixInt()
+	if err != nil {
+		return nil, fmt.Errorf("read number of outputs: %w", err)
+	}
+
+	outputs := make(map[string]*string)
+	for i := uint64(0); i < n.Value; i++ {
+		name, err := c.readNixString()
+		if err != nil {
+			return nil, fmt.Errorf("read output name: %w", err)
+		}
+		// An empty path denotes an output whose path isn't known yet.
+		path, err := c.readNixString()
+		if err != nil {
+			return nil, fmt.Errorf("read store path of output %s: %w", name, err)
+		}
+		if path == "" {
+			outputs[string(name)] = nil
+			continue
+		}
+		p := string(path)
+		outputs[string(name)] = &p
+	}
+
+	return outputs, nil
+}
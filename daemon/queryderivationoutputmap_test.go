@@ -0,0 +1,28 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryDerivationOutputMap(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	const drvPath = "/nix/store/00000000000000000000000000000000-foo.drv"
+	c, sent, _ := testConn(37,
+		last,
+		u64(2),
+		str("dev"), str(""),
+		str("out"), str("/nix/store/00000000000000000000000000000000-foo"),
+	)
+
+	outputs, err := c.QueryDerivationOutputMap(drvPath)
+	require.NoError(err)
+	out := "/nix/store/00000000000000000000000000000000-foo"
+	assert.Equal(map[string]*string{"dev": nil, "out": &out}, outputs)
+	assert.Equal(cat(u64(uint64(opcode.QueryDerivationOutputMap)), str(drvPath)), sent.Bytes())
+}
@@ -0,0 +1,69 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/msanft/proton/internal/protocol/operation"
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// MissingPaths describes what the daemon would need to do to realise a set of derived paths.
+type MissingPaths struct {
+	// WillBuild are the derivations that would be built.
+	WillBuild []string
+	// WillSubstitute are the store paths that would be substituted.
+	WillSubstitute []string
+	// Unknown are the store paths for which it isn't known how to realise them.
+	Unknown []string
+	// DownloadSize is the total size of the archives to download in bytes.
+	DownloadSize uint64
+	// NarSize is the total size of the unpacked substituted paths in bytes.
+	NarSize uint64
+}
+
+// QueryMissing returns what the daemon would need to build or substitute
+// to realise the given derived paths, without doing so.
+func (c *Conn) QueryMissing(paths []DerivedPath) (MissingPaths, error) {
+	strs, err := c.derivedPathStrings(paths)
+	if err != nil {
+		return MissingPaths{}, fmt.Errorf("encode derived paths: %w", err)
+	}
+	p := pseudo.StringList(strs)
+	if err := c.writeNix(operation.NewOperation(
+		opcode.QueryMissing,
+		&p,
+	)); err != nil {
+		return MissingPaths{}, fmt.Errorf("write derived paths to connection: %w", err)
+	}
+
+	if err := c.ParseStderr(); err != nil {
+		return MissingPaths{}, fmt.Errorf("receive stderr: %w", err)
+	}
+
+	var missing MissingPaths
+
+	if missing.WillBuild, err = c.readNixStringList(); err != nil {
+		return MissingPaths{}, fmt.Errorf("read paths to build: %w", err)
+	}
+	if missing.WillSubstitute, err = c.readNixStringList(); err != nil {
+		return MissingPaths{}, fmt.Errorf("read paths to substitute: %w", err)
+	}
+	if missing.Unknown, err = c.readNixStringList(); err != nil {
+		return MissingPaths{}, fmt.Errorf("read unknown paths: %w", err)
+	}
+
+	downloadSize, err := c.readNixInt()
+	if err != nil {
+		return MissingPaths{}, fmt.Errorf("read download size: %w", err)
+	}
+	missing.DownloadSize = downloadSize.Value
+
+	narSize, err := c.readNixInt()
+	if err != nil {
+		return MissingPaths{}, fmt.Errorf("read NAR size: %w", err)
+	}
+	missing.NarSize = narSize.Value
+
+	return missing, nil
+}
@@ -0,0 +1,38 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/msanft/proton/internal/protocol/operation"
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// QueryPathFromHashPart returns the full store path whose hash part
+// (the 32 characters following the store directory) is the given one.
+// If there is no such path in the daemon's store, the returned boolean is
+// false and no error is returned.
+func (c *Conn) QueryPathFromHashPart(hashPart string) (string, bool, error) {
+	h := pseudo.String(hashPart)
+	if err := c.writeNix(operation.NewOperation(
+		opcode.QueryPathFromHashPart,
+		&h,
+	)); err != nil {
+		return "", false, fmt.Errorf("write hash part to connection: %w", err)
+	}
+
+	if err := c.ParseStderr(); err != nil {
+		return "", false, fmt.Errorf("receive stderr: %w", err)
+	}
+
+	// The daemon answers with an empty string if there is no such path.
+	path, err := c.readNixString()
+	if err != nil {
+		return "", false, fmt.Errorf("read store path: %w", err)
+	}
+	if path == "" {
+		return "", false, nil
+	}
+
+	return string(path), true, nil
+}
@@ -0,0 +1,40 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/msanft/proton/internal/protocol/operation"
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// QueryPathInfo returns the metadata the daemon's store holds about the given store path.
+// If the path is not valid, the returned boolean is false and no error is returned.
+func (c *Conn) QueryPathInfo(path string) (ValidPathInfo, bool, error) {
+	p := pseudo.String(path)
+	if err := c.writeNix(operation.NewOperation(
+		opcode.QueryPathInfo,
+		&p,
+	)); err != nil {
+		return ValidPathInfo{}, false, fmt.Errorf("write store path to connection: %w", err)
+	}
+
+	if err := c.ParseStderr(); err != nil {
+		return ValidPathInfo{}, false, fmt.Errorf("receive stderr: %w", err)
+	}
+
+	valid, err := c.readNixBool()
+	if err != nil {
+		return ValidPathInfo{}, false, fmt.Errorf("read validity: %w", err)
+	}
+	if !valid {
+		return ValidPathInfo{}, false, nil
+	}
+
+	info, err := c.readUnkeyedValidPathInfo(path)
+	if err != nil {
+		return ValidPathInfo{}, false, fmt.Errorf("read path info: %w", err)
+	}
+
+	return info, true, nil
+}
@@ -0,0 +1,58 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryPathInfo(t *testing.T) {
+	const path = "/nix/store/00000000000000000000000000000000-foo"
+
+	t.Run("valid", func(t *testing.T) {
+		require := require.New(t)
+		assert := assert.New(t)
+
+		c, sent, _ := testConn(37,
+			last,
+			u64(1),
+			str("/nix/store/00000000000000000000000000000000-foo.drv"),
+			str("1b2m2y8asgtpgatdkjmz5dyrfhg8nnnhpzldf1vrcfz5mvgcnl0q"),
+			strs("/nix/store/00000000000000000000000000000000-bar", path),
+			u64(1700000000),
+			u64(1024),
+			u64(1),
+			strs("cache.nixos.org-1:AAAA"),
+			str(""),
+		)
+
+		info, valid, err := c.QueryPathInfo(path)
+		require.NoError(err)
+		assert.True(valid)
+		assert.Equal(ValidPathInfo{
+			Path:             path,
+			Deriver:          "/nix/store/00000000000000000000000000000000-foo.drv",
+			NarHash:          "1b2m2y8asgtpgatdkjmz5dyrfhg8nnnhpzldf1vrcfz5mvgcnl0q",
+			References:       []string{"/nix/store/00000000000000000000000000000000-bar", path},
+			RegistrationTime: time.Unix(1700000000, 0),
+			NarSize:          1024,
+			Ultimate:         true,
+			Signatures:       []string{"cache.nixos.org-1:AAAA"},
+		}, info)
+		assert.Equal(cat(u64(26), str(path)), sent.Bytes())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		require := require.New(t)
+		assert := assert.New(t)
+
+		c, _, _ := testConn(37, last, u64(0))
+
+		info, valid, err := c.QueryPathInfo(path)
+		require.NoError(err)
+		assert.False(valid)
+		assert.Equal(ValidPathInfo{}, info)
+	})
+}
@@ -0,0 +1,61 @@
+package daemon
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// QueryRealisation returns the realisation of the given derivation output.
+// If the output hasn't been realised, the returned boolean is false and no error is returned.
+// Daemons older than protocol version 1.31 only report the output path, so the
+// signatures and dependent realisations of the result are empty.
+func (c *Conn) QueryRealisation(id DrvOutput) (Realisation, bool, error) {
+	if err := c.requireMinor(27); err != nil {
+		return Realisation{}, false, err
+	}
+
+	if err := c.writeNix(opcode.QueryRealisation); err != nil {
+		return Realisation{}, false, fmt.Errorf("write operation to connection: %w", err)
+	}
+	if err := c.writeNix(pseudo.String(id.String())); err != nil {
+		return Realisation{}, false, fmt.Errorf("write derivation output ID to connection: %w", err)
+	}
+
+	if err := c.ParseStderr(); err != nil {
+		return Realisation{}, false, fmt.Errorf("receive stderr: %w", err)
+	}
+
+	if c.version.Minor() < 31 {
+		// Older daemons answer with a set of at most one output path.
+		outPaths, err := c.readNixStringList()
+		if err != nil {
+			return Realisation{}, false, fmt.Errorf("read output paths: %w", err)
+		}
+		if len(outPaths) == 0 {
+			return Realisation{}, false, nil
+		}
+		// Unlike in realisations, the path includes the store directory.
+		return Realisation{ID: id, OutPath: path.Base(outPaths[0])}, true, nil
+	}
+
+	n, err := c.readNixInt()
+	if err != nil {
+		return Realisation{}, false, fmt.Errorf("read number of realisations: %w", err)
+	}
+	var realisations []Realisation
+	for i := uint64(0); i < n.Value; i++ {
+		realisation, err := c.readRealisation()
+		if err != nil {
+			return Realisation{}, false, fmt.Errorf("read realisation: %w", err)
+		}
+		realisations = append(realisations, realisation)
+	}
+	if len(realisations) == 0 {
+		return Realisation{}, false, nil
+	}
+
+	return realisations[0], true, nil
+}
@@ -0,0 +1,67 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryRealisation(t *testing.T) {
+	const drvHash = "sha256:1b2m2y8asgtpgatdkjmz5dyrfhg8nnnhpzldf1vrcfz5mvgcnl0q"
+	id := DrvOutput{DrvHash: drvHash, OutputName: "out"}
+	request := cat(u64(uint64(opcode.QueryRealisation)), str(drvHash+"!out"))
+
+	t.Run("v1.30", func(t *testing.T) {
+		require := require.New(t)
+		assert := assert.New(t)
+
+		c, sent, _ := testConn(30, last, strs("/nix/store/00000000000000000000000000000000-foo"))
+		realisation, ok, err := c.QueryRealisation(id)
+		require.NoError(err)
+		assert.True(ok)
+		assert.Equal(Realisation{ID: id, OutPath: "00000000000000000000000000000000-foo"}, realisation)
+		assert.Equal(request, sent.Bytes())
+	})
+
+	t.Run("v1.30 unknown", func(t *testing.T) {
+		require := require.New(t)
+		assert := assert.New(t)
+
+		c, _, _ := testConn(30, last, strs())
+		_, ok, err := c.QueryRealisation(id)
+		require.NoError(err)
+		assert.False(ok)
+	})
+
+	t.Run("v1.31", func(t *testing.T) {
+		require := require.New(t)
+		assert := assert.New(t)
+
+		c, sent, _ := testConn(31, last, strs(
+			`{"id":"`+drvHash+`!out","outPath":"00000000000000000000000000000000-foo",`+
+				`"signatures":["cache.example.com-1:AAAA"],"dependentRealisations":{}}`,
+		))
+		realisation, ok, err := c.QueryRealisation(id)
+		require.NoError(err)
+		assert.True(ok)
+		assert.Equal(Realisation{
+			ID:                    id,
+			OutPath:               "00000000000000000000000000000000-foo",
+			Signatures:            []string{"cache.example.com-1:AAAA"},
+			DependentRealisations: map[DrvOutput]string{},
+		}, realisation)
+		assert.Equal(request, sent.Bytes())
+	})
+
+	t.Run("v1.31 unknown", func(t *testing.T) {
+		require := require.New(t)
+		assert := assert.New(t)
+
+		c, _, _ := testConn(31, last, strs())
+		_, ok, err := c.QueryRealisation(id)
+		require.NoError(err)
+		assert.False(ok)
+	})
+}
@@ -0,0 +1,32 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/msanft/proton/internal/protocol/operation"
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// QueryReferrers returns the store paths that refer to the given store path,
+// i.e. the paths that depend on it.
+func (c *Conn) QueryReferrers(path string) ([]string, error) {
+	p := pseudo.String(path)
+	if err := c.writeNix(operation.NewOperation(
+		opcode.QueryReferrers,
+		&p,
+	)); err != nil {
+		return nil, fmt.Errorf("write store path to connection: %w", err)
+	}
+
+	if err := c.ParseStderr(); err != nil {
+		return nil, fmt.Errorf("receive stderr: %w", err)
+	}
+
+	referrers, err := c.readNixStringList()
+	if err != nil {
+		return nil, fmt.Errorf("read referrers: %w", err)
+	}
+
+	return referrers, nil
+}
@@ -0,0 +1,95 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/msanft/proton/internal/primitive"
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// SubstitutablePathInfo is the information a substituter provides about a store path.
+type SubstitutablePathInfo struct {
+	// Deriver is the store path of the derivation that produced the path.
+	// It is empty if the deriver is unknown.
+	Deriver string
+	// References are the store paths the path refers to.
+	References []string
+	// DownloadSize is the size of the compressed archive in bytes.
+	DownloadSize uint64
+	// NarSize is the size of the path's NAR serialization in bytes.
+	NarSize uint64
+}
+
+// QuerySubstitutablePathInfos returns information about the given store paths
+// from the daemon's configured substituters, keyed by store path.
+// Paths that cannot be substituted are absent from the result.
+func (c *Conn) QuerySubstitutablePathInfos(paths []string) (map[string]SubstitutablePathInfo, error) {
+	if err := c.writeNix(opcode.QuerySubstitutablePathInfos); err != nil {
+		return nil, fmt.Errorf("write operation to connection: %w", err)
+	}
+	if c.version.Minor() < 22 {
+		if err := c.writeNix(pseudo.StringList(paths)); err != nil {
+			return nil, fmt.Errorf("write store paths to connection: %w", err)
+		}
+	} else {
+		// Newer daemons expect a map from store path to content address.
+		// We don't know the content addresses, so we leave them empty.
+		if err := c.writeNix(primitive.NewInt(uint64(len(paths)))); err != nil {
+			return nil, fmt.Errorf("write number of store paths to connection: %w", err)
+		}
+		for _, path := range paths {
+			if err := c.writeNix(pseudo.String(path)); err != nil {
+				return nil, fmt.Errorf("write store path to connection: %w", err)
+			}
+			if err := c.writeNix(pseudo.String("")); err != nil {
+				return nil, fmt.Errorf("write content address to connection: %w", err)
+			}
+		}
+	}
+
+	if err := c.ParseStderr(); err != nil {
+		return nil, fmt.Errorf("receive stderr: %w", err)
+	}
+
+	n, err := c.readNixInt()
+	if err != nil {
+		return nil, fmt.Errorf("read number of path infos: %w", err)
+	}
+
+	infos := make(map[string]SubstitutablePathInfo)
+	for i := uint64(0); i < n.Value; i++ {
+		path, err := c.readNixString()
+		if err != nil {
+			return nil, fmt.Errorf("read store path: %w", err)
+		}
+
+		var info SubstitutablePathInfo
+
+		deriver, err := c.readNixString()
+		if err != nil {
+			return nil, fmt.Errorf("read deriver of %s: %w", path, err)
+		}
+		info.Deriver = string(deriver)
+
+		if info.References, err = c.readNixStringList(); err != nil {
+			return nil, fmt.Errorf("read references of %s: %w", path, err)
+		}
+
+		downloadSize, err := c.readNixInt()
+		if err != nil {
+			return nil, fmt.Errorf("read download size of %s: %w", path, err)
+		}
+		info.DownloadSize = downloadSize.Value
+
+		narSize, err := c.readNixInt()
+		if err != nil {
+			return nil, fmt.Errorf("read NAR size of %s: %w", path, err)
+		}
+		info.NarSize = narSize.Value
+
+		infos[string(path)] = info
+	}
+
+	return infos, nil
+}
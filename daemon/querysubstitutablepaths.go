@@ -0,0 +1,32 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/msanft/proton/internal/protocol/operation"
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// QuerySubstitutablePaths returns the subset of the given store paths that
+// can be substituted from the daemon's configured substituters.
+func (c *Conn) QuerySubstitutablePaths(paths []string) ([]string, error) {
+	p := pseudo.StringList(paths)
+	if err := c.writeNix(operation.NewOperation(
+		opcode.QuerySubstitutablePaths,
+		&p,
+	)); err != nil {
+		return nil, fmt.Errorf("write store paths to connection: %w", err)
+	}
+
+	if err := c.ParseStderr(); err != nil {
+		return nil, fmt.Errorf("receive stderr: %w", err)
+	}
+
+	substitutable, err := c.readNixStringList()
+	if err != nil {
+		return nil, fmt.Errorf("read substitutable paths: %w", err)
+	}
+
+	return substitutable, nil
+}
@@ -0,0 +1,32 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/msanft/proton/internal/protocol/operation"
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// QueryValidDerivers returns the valid derivations (.drv files) in the daemon's
+// store that produce the given store path as one of their outputs.
+func (c *Conn) QueryValidDerivers(path string) ([]string, error) {
+	p := pseudo.String(path)
+	if err := c.writeNix(operation.NewOperation(
+		opcode.QueryValidDerivers,
+		&p,
+	)); err != nil {
+		return nil, fmt.Errorf("write store path to connection: %w", err)
+	}
+
+	if err := c.ParseStderr(); err != nil {
+		return nil, fmt.Errorf("receive stderr: %w", err)
+	}
+
+	derivers, err := c.readNixStringList()
+	if err != nil {
+		return nil, fmt.Errorf("read derivers: %w", err)
+	}
+
+	return derivers, nil
+}
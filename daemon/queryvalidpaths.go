@@ -0,0 +1,39 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/msanft/proton/internal/protocol/operation"
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// QueryValidPaths returns the subset of the given store paths that is valid
+// on the daemon's store.
+// If substitute is set, the daemon tries to substitute invalid paths before
+// answering. Daemons older than protocol version 1.27 ignore this flag.
+func (c *Conn) QueryValidPaths(paths []string, substitute bool) ([]string, error) {
+	p := pseudo.StringList(paths)
+	if err := c.writeNix(operation.NewOperation(
+		opcode.QueryValidPaths,
+		&p,
+	)); err != nil {
+		return nil, fmt.Errorf("write store paths to connection: %w", err)
+	}
+	if c.version.Minor() >= 27 {
+		if err := c.writeNix(pseudo.Bool(substitute)); err != nil {
+			return nil, fmt.Errorf("write substitute flag to connection: %w", err)
+		}
+	}
+
+	if err := c.ParseStderr(); err != nil {
+		return nil, fmt.Errorf("receive stderr: %w", err)
+	}
+
+	valid, err := c.readNixStringList()
+	if err != nil {
+		return nil, fmt.Errorf("read valid paths: %w", err)
+	}
+
+	return valid, nil
+}
@@ -0,0 +1,96 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// DrvOutput identifies an output of a content-addressed derivation.
+type DrvOutput struct {
+	// DrvHash is the hash modulo of the derivation, e.g. "sha256:...".
+	DrvHash string
+	// OutputName is the name of the output, e.g. "out".
+	OutputName string
+}
+
+// ParseDrvOutput parses a derivation output ID of the form "sha256:...!out".
+func ParseDrvOutput(s string) (DrvOutput, error) {
+	hash, name, found := strings.Cut(s, "!")
+	if !found || hash == "" || name == "" {
+		return DrvOutput{}, fmt.Errorf("invalid derivation output ID %q", s)
+	}
+	return DrvOutput{DrvHash: hash, OutputName: name}, nil
+}
+
+// String returns the ID of the derivation output, e.g. "sha256:...!out".
+func (o DrvOutput) String() string {
+	return o.DrvHash + "!" + o.OutputName
+}
+
+// MarshalText encodes the derivation output as its ID.
+func (o DrvOutput) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+// UnmarshalText decodes the derivation output from its ID.
+func (o *DrvOutput) UnmarshalText(text []byte) error {
+	parsed, err := ParseDrvOutput(string(text))
+	if err != nil {
+		return err
+	}
+	*o = parsed
+	return nil
+}
+
+// Realisation records which store path an output of a content-addressed
+// derivation was built to.
+type Realisation struct {
+	// ID identifies the derivation output.
+	ID DrvOutput `json:"id"`
+	// OutPath is the store path the output was built to. Like all store paths
+	// in realisations, it is given without the store directory.
+	OutPath string `json:"outPath"`
+	// Signatures are the signatures over the realisation.
+	Signatures []string `json:"signatures"`
+	// DependentRealisations maps the IDs of the realisations this one depends on
+	// to their output paths.
+	DependentRealisations map[DrvOutput]string `json:"dependentRealisations"`
+}
+
+// readRealisation reads a JSON-encoded realisation from the connection.
+func (c *Conn) readRealisation() (Realisation, error) {
+	raw, err := c.readNixString()
+	if err != nil {
+		return Realisation{}, fmt.Errorf("reading realisation: %w", err)
+	}
+
+	var r Realisation
+	if err := json.Unmarshal([]byte(raw), &r); err != nil {
+		return Realisation{}, fmt.Errorf("decoding realisation: %w", err)
+	}
+	return r, nil
+}
+
+// writeRealisation writes the given realisation JSON-encoded to the connection.
+func (c *Conn) writeRealisation(r Realisation) error {
+	// The daemon treats missing lists as empty, but can't decode the null
+	// that nil encodes to, so send empty lists instead.
+	if r.Signatures == nil {
+		r.Signatures = []string{}
+	}
+	if r.DependentRealisations == nil {
+		r.DependentRealisations = map[DrvOutput]string{}
+	}
+
+	raw, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("encoding realisation: %w", err)
+	}
+	if err := c.writeNix(pseudo.String(raw)); err != nil {
+		return fmt.Errorf("writing realisation: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package daemon
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDrvOutput(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	o, err := ParseDrvOutput("sha256:1b2m2y8asgtpgatdkjmz5dyrfhg8nnnhpzldf1vrcfz5mvgcnl0q!out")
+	require.NoError(err)
+	assert.Equal(DrvOutput{DrvHash: "sha256:1b2m2y8asgtpgatdkjmz5dyrfhg8nnnhpzldf1vrcfz5mvgcnl0q", OutputName: "out"}, o)
+	assert.Equal("sha256:1b2m2y8asgtpgatdkjmz5dyrfhg8nnnhpzldf1vrcfz5mvgcnl0q!out", o.String())
+
+	_, err = ParseDrvOutput("sha256:1b2m2y8asgtpgatdkjmz5dyrfhg8nnnhpzldf1vrcfz5mvgcnl0q")
+	assert.Error(err)
+	_, err = ParseDrvOutput("sha256:1b2m2y8asgtpgatdkjmz5dyrfhg8nnnhpzldf1vrcfz5mvgcnl0q!")
+	assert.Error(err)
+}
+
+func TestRealisationJSON(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	raw := `{
+		"id": "sha256:1b2m2y8asgtpgatdkjmz5dyrfhg8nnnhpzldf1vrcfz5mvgcnl0q!out",
+		"outPath": "g1w7hy3qg1w7hy3qg1w7hy3qg1w7hy3q-foo",
+		"signatures": ["cache.example.org-1:c2lnbmF0dXJl"],
+		"dependentRealisations": {
+			"sha256:0000000000000000000000000000000000000000000000000000!dev": "00000000000000000000000000000000-bar-dev"
+		}
+	}`
+
+	var r Realisation
+	require.NoError(json.Unmarshal([]byte(raw), &r))
+	assert.Equal(Realisation{
+		ID:         DrvOutput{DrvHash: "sha256:1b2m2y8asgtpgatdkjmz5dyrfhg8nnnhpzldf1vrcfz5mvgcnl0q", OutputName: "out"},
+		OutPath:    "g1w7hy3qg1w7hy3qg1w7hy3qg1w7hy3q-foo",
+		Signatures: []string{"cache.example.org-1:c2lnbmF0dXJl"},
+		DependentRealisations: map[DrvOutput]string{
+			{DrvHash: "sha256:0000000000000000000000000000000000000000000000000000", OutputName: "dev"}: "00000000000000000000000000000000-bar-dev",
+		},
+	}, r)
+
+	encoded, err := json.Marshal(r)
+	require.NoError(err)
+	assert.JSONEq(raw, string(encoded))
+}
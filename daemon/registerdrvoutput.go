@@ -0,0 +1,39 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// RegisterDrvOutput records the given realisation in the daemon's store.
+// Daemons older than protocol version 1.31 only record the ID and the output path,
+// and drop the signatures and dependent realisations.
+func (c *Conn) RegisterDrvOutput(realisation Realisation) error {
+	if err := c.requireMinor(27); err != nil {
+		return err
+	}
+
+	if err := c.writeNix(opcode.RegisterDrvOutput); err != nil {
+		return fmt.Errorf("write operation to connection: %w", err)
+	}
+	if c.version.Minor() < 31 {
+		if err := c.writeNix(pseudo.String(realisation.ID.String())); err != nil {
+			return fmt.Errorf("write derivation output ID to connection: %w", err)
+		}
+		if err := c.writeNix(pseudo.String(realisation.OutPath)); err != nil {
+			return fmt.Errorf("write output path to connection: %w", err)
+		}
+	} else {
+		if err := c.writeRealisation(realisation); err != nil {
+			return fmt.Errorf("write realisation to connection: %w", err)
+		}
+	}
+
+	if err := c.ParseStderr(); err != nil {
+		return fmt.Errorf("receive stderr: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterDrvOutput(t *testing.T) {
+	const drvHash = "sha256:1b2m2y8asgtpgatdkjmz5dyrfhg8nnnhpzldf1vrcfz5mvgcnl0q"
+	realisation := Realisation{
+		ID:         DrvOutput{DrvHash: drvHash, OutputName: "out"},
+		OutPath:    "00000000000000000000000000000000-foo",
+		Signatures: []string{"cache.example.com-1:AAAA"},
+	}
+
+	t.Run("v1.30", func(t *testing.T) {
+		require := require.New(t)
+		assert := assert.New(t)
+
+		c, sent, _ := testConn(30, last)
+		require.NoError(c.RegisterDrvOutput(realisation))
+		assert.Equal(cat(
+			u64(uint64(opcode.RegisterDrvOutput)),
+			str(drvHash+"!out"), str("00000000000000000000000000000000-foo"),
+		), sent.Bytes())
+	})
+
+	t.Run("v1.31", func(t *testing.T) {
+		require := require.New(t)
+		assert := assert.New(t)
+
+		c, sent, _ := testConn(31, last)
+		require.NoError(c.RegisterDrvOutput(realisation))
+		assert.Equal(cat(
+			u64(uint64(opcode.RegisterDrvOutput)),
+			str(`{"id":"`+drvHash+`!out","outPath":"00000000000000000000000000000000-foo",`+
+				`"signatures":["cache.example.com-1:AAAA"],"dependentRealisations":{}}`),
+		), sent.Bytes())
+	})
+}
@@ -0,0 +1,121 @@
+package daemon
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/msanft/proton/internal/primitive"
+	"github.com/msanft/proton/internal/protocol"
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/msanft/proton/internal/protocol/operation"
+	"github.com/msanft/proton/internal/protocol/verbosity"
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// SandboxMode determines whether builds run in a sandbox.
+type SandboxMode string
+
+const (
+	// SandboxEnabled runs all builds in a sandbox.
+	SandboxEnabled SandboxMode = "true"
+	// SandboxDisabled runs builds without a sandbox.
+	SandboxDisabled SandboxMode = "false"
+	// SandboxRelaxed runs builds in a sandbox, except for fixed-output
+	// derivations and derivations with __noChroot set.
+	SandboxRelaxed SandboxMode = "relaxed"
+)
+
+// Options are the settings the daemon uses for the operations on a connection.
+// The zero value keeps the defaults of a Nix client without configuration.
+type Options struct {
+	// KeepFailed keeps the build directories of failed builds.
+	KeepFailed bool
+	// KeepGoing continues building other derivations if a build fails.
+	KeepGoing bool
+	// Fallback builds derivations locally if substituting them fails.
+	Fallback bool
+	// MaxJobs is the maximum number of builds run in parallel.
+	// If it points to zero, only remote builders are used, and if it is nil, it is 1.
+	MaxJobs *uint64
+	// MaxSilentTime aborts builds that produce no output for this long.
+	// If it is zero, there is no limit.
+	MaxSilentTime time.Duration
+	// Cores is the number of CPU cores a single build may use.
+	// If it is zero, builds may use all cores.
+	Cores uint64
+	// NoSubstitutes forbids substituting paths instead of building them.
+	NoSubstitutes bool
+
+	// Substituters replaces the daemon's substituter URLs.
+	// If it is nil, the daemon's substituters are kept.
+	Substituters []string
+	// ExtraSubstituters are substituter URLs used in addition to the daemon's.
+	ExtraSubstituters []string
+	// Sandbox overrides whether builds run in a sandbox.
+	// If it is empty, the daemon's setting is kept.
+	Sandbox SandboxMode
+	// Builders replaces the daemon's remote builders, in the format of the
+	// "builders" setting. If it is nil, the daemon's builders are kept,
+	// and if it is empty but not nil, remote builds are disabled.
+	Builders []string
+
+	// Overrides are further settings by name, like "extra-trusted-public-keys".
+	// The fields above take precedence over the same settings in Overrides.
+	Overrides map[string]string
+}
+
+// overrides returns the settings the daemon applies on top of the fixed options.
+func (o Options) overrides() map[pseudo.String]pseudo.String {
+	overrides := make(map[pseudo.String]pseudo.String, len(o.Overrides))
+	for k, v := range o.Overrides {
+		overrides[pseudo.String(k)] = pseudo.String(v)
+	}
+	if o.Substituters != nil {
+		overrides["substituters"] = pseudo.String(strings.Join(o.Substituters, " "))
+	}
+	if len(o.ExtraSubstituters) > 0 {
+		overrides["extra-substituters"] = pseudo.String(strings.Join(o.ExtraSubstituters, " "))
+	}
+	if o.Sandbox != "" {
+		overrides["sandbox"] = pseudo.String(o.Sandbox)
+	}
+	if o.Builders != nil {
+		overrides["builders"] = pseudo.String(strings.Join(o.Builders, ";"))
+	}
+	return overrides
+}
+
+// SetOptions sets the daemon's settings for all following operations on the connection.
+func (c *Conn) SetOptions(opts Options) error {
+	maxJobs := uint64(1)
+	if opts.MaxJobs != nil {
+		maxJobs = *opts.MaxJobs
+	}
+
+	o := protocol.SetOptions{
+		KeepFailing:   pseudo.Bool(opts.KeepFailed),
+		KeepGoing:     pseudo.Bool(opts.KeepGoing),
+		TryFallback:   pseudo.Bool(opts.Fallback),
+		Verbosity:     verbosity.Info,
+		MaxBuildJobs:  primitive.NewInt(maxJobs),
+		MaxSilentTime: primitive.NewInt(uint64(opts.MaxSilentTime / time.Second)),
+		// The daemon only forwards build logs if the build verbosity is verbosity.Error.
+		BuildVerbosity: verbosity.Error,
+		BuildCores:     primitive.NewInt(opts.Cores),
+		UseSubstitutes: pseudo.Bool(!opts.NoSubstitutes),
+		Options:        opts.overrides(),
+	}
+	if err := c.writeNix(operation.NewOperation(
+		opcode.SetOptions,
+		&o,
+	)); err != nil {
+		return fmt.Errorf("write options to connection: %w", err)
+	}
+
+	if err := c.ParseStderr(); err != nil {
+		return fmt.Errorf("receive stderr: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/msanft/proton/internal/pseudo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionsOverrides(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Empty(Options{}.overrides())
+
+	var opts Options
+	opts.Substituters = []string{"https://cache.nixos.org", "https://cache.example.com"}
+	opts.ExtraSubstituters = []string{"https://ci.example.com"}
+	opts.Sandbox = SandboxRelaxed
+	opts.Builders = []string{}
+	opts.Overrides = map[string]string{
+		"sandbox":                   "false",
+		"extra-trusted-public-keys": "ci.example.com-1:AAAA",
+	}
+	assert.Equal(map[pseudo.String]pseudo.String{
+		"substituters":              "https://cache.nixos.org https://cache.example.com",
+		"extra-substituters":        "https://ci.example.com",
+		"sandbox":                   "relaxed",
+		"builders":                  "",
+		"extra-trusted-public-keys": "ci.example.com-1:AAAA",
+	}, opts.overrides())
+}
+
+func TestSetOptions(t *testing.T) {
+	t.Run("zero value", func(t *testing.T) {
+		require := require.New(t)
+		assert := assert.New(t)
+
+		c, sent, _ := testConn(37, last)
+		require.NoError(c.SetOptions(Options{ExtraSubstituters: []string{"https://ci.example.com"}}))
+		assert.Equal(cat(
+			u64(uint64(opcode.SetOptions)),
+			u64(0), // keep failed
+			u64(0), // keep going
+			u64(0), // fallback
+			u64(3), // verbosity: info
+			u64(1), // max jobs
+			u64(0), // max silent time
+			u64(0), // obsolete build hook flag
+			u64(0), // build verbosity: error, so that build logs are forwarded
+			u64(0), // obsolete log type
+			u64(0), // obsolete print build trace flag
+			u64(0), // cores
+			u64(1), // use substitutes
+			u64(1), str("extra-substituters"), str("https://ci.example.com"),
+		), sent.Bytes())
+	})
+
+	t.Run("all fixed options", func(t *testing.T) {
+		require := require.New(t)
+		assert := assert.New(t)
+
+		maxJobs := uint64(0)
+		c, sent, _ := testConn(37, last)
+		require.NoError(c.SetOptions(Options{
+			KeepFailed:    true,
+			KeepGoing:     true,
+			Fallback:      true,
+			MaxJobs:       &maxJobs,
+			MaxSilentTime: time.Minute,
+			Cores:         4,
+			NoSubstitutes: true,
+			Sandbox:       SandboxEnabled,
+		}))
+		assert.Equal(cat(
+			u64(uint64(opcode.SetOptions)),
+			u64(1), u64(1), u64(1), u64(3), u64(0), u64(60), u64(0), u64(0), u64(0), u64(0), u64(4), u64(0),
+			u64(1), str("sandbox"), str("true"),
+		), sent.Bytes())
+	})
+}
@@ -7,89 +7,248 @@ import (
 
 	"github.com/msanft/proton/internal/primitive"
 	"github.com/msanft/proton/internal/protocol/stderr"
-	"github.com/msanft/proton/internal/pseudo"
 )
 
-// nixWire is an interface for types that can be (un)marshaled from/into
-// the Nix wire format.
-type nixWire interface {
-	MarshalNix() ([]byte, error)
-	UnmarshalNix([]byte) error
-	Size() uint64
+// ParseStderr reads the daemon's stderr messages from the connection until the
+// last marker is received. Log messages are written into the connection's stderr writer.
+// If the daemon reports an error, it is returned.
+func (c *Conn) ParseStderr() error {
+	return c.parseStderr(nil)
 }
 
-// ReceiveStderr tries to read all leftover error messages from the daemon, if any.
-// Error messages are written into the writer.
-func (c *Conn) ParseStderr() (rest []byte, retErr error) {
-	data, err := c.readUntilLastStderr()
-	if err != nil {
-		retErr = fmt.Errorf("reading stderr: %w", err)
-		return
-	}
-
+// parseStderr is like ParseStderr, but additionally answers the daemon's requests
+// for data with data read from src. Such requests are an error if src is nil.
+func (c *Conn) parseStderr(src io.Reader) error {
 	for {
-		var marker stderr.Marker
-		if err := marker.UnmarshalNix(data); err != nil {
-			retErr = errors.Join(retErr, fmt.Errorf("unmarshaling stderr marker: %w", err))
-			return
+		rawMarker, err := c.readNixInt()
+		if err != nil {
+			return fmt.Errorf("reading stderr marker: %w", err)
 		}
-		data = data[marker.Size():]
 
-		var body nixWire
-		switch marker {
+		switch marker := stderr.Marker(rawMarker.Value); marker {
 		case stderr.MarkerLast:
 			// Parsing is done
-			return
+			return nil
 		case stderr.MarkerWrite, stderr.MarkerNext:
-			body = new(pseudo.String)
+			msg, err := c.readNixString()
+			if err != nil {
+				return fmt.Errorf("reading log message: %w", err)
+			}
+			if _, err := c.stderr.Write([]byte(msg)); err != nil {
+				return fmt.Errorf("writing log message: %w", err)
+			}
+		case stderr.MarkerRead:
+			if err := c.answerRead(src); err != nil {
+				return fmt.Errorf("answering request for data: %w", err)
+			}
 		case stderr.MarkerError:
-			body = &stderr.Error{}
-		case stderr.MarkerResult:
-			body = &stderr.Result{}
+			e, err := c.readStderrError()
+			if err != nil {
+				return fmt.Errorf("reading error: %w", err)
+			}
+			return e
 		case stderr.MarkerStartActivity:
-			body = &stderr.StartActivity{}
+			activity, err := c.readStderrStartActivity()
+			if err != nil {
+				return fmt.Errorf("reading start of activity: %w", err)
+			}
+			if err := c.logActivity(activity); err != nil {
+				return fmt.Errorf("writing activity: %w", err)
+			}
 		case stderr.MarkerStopActivity:
-			body = &primitive.Int{}
-		default:
-			// we read into data
-			rest, err = marker.MarshalNix()
+			if _, err := c.readNixInt(); err != nil {
+				return fmt.Errorf("reading stopped activity: %w", err)
+			}
+		case stderr.MarkerResult:
+			result, err := c.readStderrResult()
 			if err != nil {
-				retErr = errors.Join(retErr, fmt.Errorf("marshaling stderr marker: %w", err))
+				return fmt.Errorf("reading activity result: %w", err)
+			}
+			if err := c.logResult(result); err != nil {
+				return fmt.Errorf("writing activity result: %w", err)
 			}
-			return
+		default:
+			return fmt.Errorf("unexpected stderr marker: %x", uint64(marker))
 		}
+	}
+}
 
-		if err := body.UnmarshalNix(data); err != nil {
-			retErr = errors.Join(retErr, fmt.Errorf("unmarshaling stderr body: %w", err))
-			return
-		}
-		data = data[body.Size():]
+// maxReadRequest is the maximum number of bytes sent at once when the daemon requests data.
+const maxReadRequest = 64 << 10
 
-		// TODO: Actual error message
-		c.stderr.Write([]byte(fmt.Sprintf("stderr: %q\n", body)))
+// answerRead reads the number of bytes the daemon requests and sends up to
+// as many bytes read from src.
+func (c *Conn) answerRead(src io.Reader) error {
+	l, err := c.readNixInt()
+	if err != nil {
+		return fmt.Errorf("reading requested length: %w", err)
 	}
+	if src == nil {
+		return errors.New("daemon requested data, but there is nothing to send")
+	}
+
+	// Sending less than requested is fine, so don't let the daemon
+	// decide how much memory we allocate.
+	buf := make([]byte, min(l.Value, maxReadRequest))
+	n, err := io.ReadAtLeast(src, buf, 1)
+	if err != nil {
+		return fmt.Errorf("reading data to send: %w", err)
+	}
+	if err := c.writeNix(primitive.NewByteBuf(buf[:n])); err != nil {
+		return fmt.Errorf("writing data: %w", err)
+	}
+	return nil
 }
 
-// readUntilLastStderr reads all data from the reader until the last stderr marker.
-func (c *Conn) readUntilLastStderr() ([]byte, error) {
-	var buf []byte
-	var rawMarker [8]byte
-	for {
-		_, readErr := c.r.Read(rawMarker[:])
-		if readErr != nil && !errors.Is(readErr, io.EOF) {
-			return nil, fmt.Errorf("reading marker: %w", readErr)
+// logActivity writes the description of a started activity, like
+// "copying path '...' from 'https://cache.nixos.org'", into the connection's stderr writer.
+func (c *Conn) logActivity(activity stderr.StartActivity) error {
+	if activity.Message.Len.Value == 0 {
+		return nil
+	}
+	_, err := fmt.Fprintf(c.stderr, "%s\n", activity.Message.Buf)
+	return err
+}
+
+// logResult writes the log lines reported as activity results into the
+// connection's stderr writer. Other results are ignored.
+func (c *Conn) logResult(result stderr.Result) error {
+	switch stderr.ResultKind(result.Kind.Value) {
+	case stderr.ResultBuildLogLine, stderr.ResultPostBuildLogLine:
+	default:
+		return nil
+	}
+	if len(result.Fields.Fields) == 0 || result.Fields.Fields[0].Kind != stderr.KindString {
+		return nil
+	}
+	_, err := fmt.Fprintf(c.stderr, "%s\n", result.Fields.Fields[0].ContentString.Buf)
+	return err
+}
+
+// readStderrError reads the body of an error message from the connection.
+func (c *Conn) readStderrError() (*Error, error) {
+	var e stderr.Error
+	var err error
+
+	// Older daemons only send the message and an exit status.
+	if c.version.Minor() < 26 {
+		if e.Message, err = c.readNixByteBuf(); err != nil {
+			return nil, fmt.Errorf("reading message: %w", err)
+		}
+		status, err := c.readNixInt()
+		if err != nil {
+			return nil, fmt.Errorf("reading status: %w", err)
 		}
-		buf = append(buf, rawMarker[:]...)
+		return &Error{Message: string(e.Message.Buf), Status: status.Value}, nil
+	}
+
+	if e.Kind, err = c.readNixByteBuf(); err != nil {
+		return nil, fmt.Errorf("reading kind: %w", err)
+	}
+	if e.Level, err = c.readNixInt(); err != nil {
+		return nil, fmt.Errorf("reading level: %w", err)
+	}
+	if e.Name, err = c.readNixByteBuf(); err != nil {
+		return nil, fmt.Errorf("reading name: %w", err)
+	}
+	if e.Message, err = c.readNixByteBuf(); err != nil {
+		return nil, fmt.Errorf("reading message: %w", err)
+	}
+	if e.HasPosition, err = c.readNixInt(); err != nil {
+		return nil, fmt.Errorf("reading hasPosition: %w", err)
+	}
 
-		var marker stderr.Marker
-		if err := marker.UnmarshalNix(rawMarker[:]); err != nil {
-			return nil, fmt.Errorf("unmarshaling marker: %w", err)
+	lenTraces, err := c.readNixInt()
+	if err != nil {
+		return nil, fmt.Errorf("reading number of traces: %w", err)
+	}
+	for i := uint64(0); i < lenTraces.Value; i++ {
+		var trace stderr.Trace
+		if trace.Position, err = c.readNixInt(); err != nil {
+			return nil, fmt.Errorf("reading trace position: %w", err)
+		}
+		if trace.Message, err = c.readNixByteBuf(); err != nil {
+			return nil, fmt.Errorf("reading trace message: %w", err)
 		}
+		e.Traces = append(e.Traces, trace)
+	}
+
+	return newError(e), nil
+}
 
-		if marker == stderr.MarkerLast || errors.Is(readErr, io.EOF) {
-			break
+// readStderrStartActivity reads the body of a start-activity message from the connection.
+func (c *Conn) readStderrStartActivity() (stderr.StartActivity, error) {
+	var s stderr.StartActivity
+	var err error
+
+	if s.Activity, err = c.readNixInt(); err != nil {
+		return stderr.StartActivity{}, fmt.Errorf("reading activity: %w", err)
+	}
+	if s.Level, err = c.readNixInt(); err != nil {
+		return stderr.StartActivity{}, fmt.Errorf("reading level: %w", err)
+	}
+	if s.Kind, err = c.readNixInt(); err != nil {
+		return stderr.StartActivity{}, fmt.Errorf("reading kind: %w", err)
+	}
+	if s.Message, err = c.readNixByteBuf(); err != nil {
+		return stderr.StartActivity{}, fmt.Errorf("reading message: %w", err)
+	}
+	if s.Fields, err = c.readStderrLoggerFields(); err != nil {
+		return stderr.StartActivity{}, fmt.Errorf("reading fields: %w", err)
+	}
+	if s.Parent, err = c.readNixInt(); err != nil {
+		return stderr.StartActivity{}, fmt.Errorf("reading parent: %w", err)
+	}
+
+	return s, nil
+}
+
+// readStderrResult reads the body of an activity result message from the connection.
+func (c *Conn) readStderrResult() (stderr.Result, error) {
+	var r stderr.Result
+	var err error
+
+	if r.Activity, err = c.readNixInt(); err != nil {
+		return stderr.Result{}, fmt.Errorf("reading activity: %w", err)
+	}
+	if r.Kind, err = c.readNixInt(); err != nil {
+		return stderr.Result{}, fmt.Errorf("reading kind: %w", err)
+	}
+	if r.Fields, err = c.readStderrLoggerFields(); err != nil {
+		return stderr.Result{}, fmt.Errorf("reading fields: %w", err)
+	}
+
+	return r, nil
+}
+
+// readStderrLoggerFields reads a length-prefixed list of logger fields from the connection.
+func (c *Conn) readStderrLoggerFields() (stderr.LoggerFields, error) {
+	lenFields, err := c.readNixInt()
+	if err != nil {
+		return stderr.LoggerFields{}, fmt.Errorf("reading number of fields: %w", err)
+	}
+
+	var fields stderr.LoggerFields
+	for i := uint64(0); i < lenFields.Value; i++ {
+		kind, err := c.readNixInt()
+		if err != nil {
+			return stderr.LoggerFields{}, fmt.Errorf("reading field kind: %w", err)
+		}
+
+		field := stderr.LoggerField{Kind: stderr.Kind(kind.Value)}
+		switch field.Kind {
+		case stderr.KindInt:
+			field.ContentInt, err = c.readNixInt()
+		case stderr.KindString:
+			field.ContentString, err = c.readNixByteBuf()
+		default:
+			return stderr.LoggerFields{}, fmt.Errorf("unknown field kind: %d", field.Kind)
+		}
+		if err != nil {
+			return stderr.LoggerFields{}, fmt.Errorf("reading field content: %w", err)
 		}
+		fields.Fields = append(fields.Fields, field)
 	}
 
-	return buf, nil
+	return fields, nil
 }
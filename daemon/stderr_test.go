@@ -0,0 +1,92 @@
+package daemon
+
+import (
+	"io"
+	"testing"
+
+	"github.com/msanft/proton/internal/protocol/stderr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStderr(t *testing.T) {
+	t.Run("log messages", func(t *testing.T) {
+		require := require.New(t)
+		assert := assert.New(t)
+
+		c, _, log := testConn(37,
+			u64(uint64(stderr.MarkerNext)), str("warning: foo\n"),
+			// Activity 1 of kind 105 (build) at level 3 (info), with one string
+			// and one integer field, and parent 0.
+			u64(uint64(stderr.MarkerStartActivity)), u64(1), u64(3), u64(105), str("building '/nix/store/foo.drv'"),
+			u64(2), u64(uint64(stderr.KindString)), str("/nix/store/foo.drv"), u64(uint64(stderr.KindInt)), u64(1),
+			u64(0),
+			u64(uint64(stderr.MarkerResult)), u64(1), u64(uint64(stderr.ResultBuildLogLine)),
+			u64(1), u64(uint64(stderr.KindString)), str("hello"),
+			// Progress results are not logged.
+			u64(uint64(stderr.MarkerResult)), u64(1), u64(uint64(stderr.ResultProgress)),
+			u64(4), u64(uint64(stderr.KindInt)), u64(1), u64(uint64(stderr.KindInt)), u64(2),
+			u64(uint64(stderr.KindInt)), u64(0), u64(uint64(stderr.KindInt)), u64(0),
+			u64(uint64(stderr.MarkerStopActivity)), u64(1),
+			last,
+			// Data after the last marker belongs to the reply.
+			u64(42),
+		)
+
+		require.NoError(c.ParseStderr())
+		assert.Equal("warning: foo\nbuilding '/nix/store/foo.drv'\nhello\n", log.String())
+
+		reply, err := c.readNixInt()
+		require.NoError(err)
+		assert.Equal(uint64(42), reply.Value)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		require := require.New(t)
+		assert := assert.New(t)
+
+		c, _, _ := testConn(37,
+			u64(uint64(stderr.MarkerError)),
+			str("Error"), u64(0), str("Error"), str("path '/nix/store/foo' is not valid"),
+			u64(0), // no position
+			u64(2),
+			u64(0), str("while fetching the input"),
+			u64(0), str("while evaluating the flake"),
+		)
+
+		err := c.ParseStderr()
+		var daemonErr *Error
+		require.ErrorAs(err, &daemonErr)
+		assert.Equal(&Error{
+			Level:   0,
+			Name:    "Error",
+			Message: "path '/nix/store/foo' is not valid",
+			Traces:  []string{"while fetching the input", "while evaluating the flake"},
+		}, daemonErr)
+	})
+
+	t.Run("legacy error", func(t *testing.T) {
+		require := require.New(t)
+		assert := assert.New(t)
+
+		c, _, _ := testConn(25,
+			u64(uint64(stderr.MarkerError)),
+			str("path '/nix/store/foo' is not valid"), u64(1),
+		)
+
+		err := c.ParseStderr()
+		var daemonErr *Error
+		require.ErrorAs(err, &daemonErr)
+		assert.Equal(&Error{Message: "path '/nix/store/foo' is not valid", Status: 1}, daemonErr)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		c, _, _ := testConn(37, u64(uint64(stderr.MarkerNext)))
+		assert.ErrorIs(t, c.ParseStderr(), io.EOF)
+	})
+
+	t.Run("unknown marker", func(t *testing.T) {
+		c, _, _ := testConn(37, u64(0x1234))
+		assert.Error(t, c.ParseStderr())
+	})
+}
@@ -0,0 +1,128 @@
+package daemon
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/msanft/proton/internal/primitive"
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// ValidPathInfo is the metadata the daemon keeps about a valid store path.
+type ValidPathInfo struct {
+	// Path is the store path the information belongs to.
+	Path string
+	// Deriver is the store path of the derivation that produced the path.
+	// It is empty if the deriver is unknown.
+	Deriver string
+	// NarHash is the base16-encoded SHA-256 hash of the path's NAR serialization.
+	NarHash string
+	// References are the store paths the path refers to.
+	References []string
+	// RegistrationTime is the time at which the path was registered as valid.
+	RegistrationTime time.Time
+	// NarSize is the size of the path's NAR serialization in bytes.
+	NarSize uint64
+	// Ultimate indicates whether the path was built locally, as opposed
+	// to being substituted or imported.
+	Ultimate bool
+	// Signatures are the signatures over the path's fingerprint.
+	Signatures []string
+	// ContentAddress is the path's content address, e.g. "fixed:r:sha256:...".
+	// It is empty if the path is input-addressed.
+	ContentAddress string
+}
+
+// readUnkeyedValidPathInfo reads the information about the given store path
+// from the connection.
+func (c *Conn) readUnkeyedValidPathInfo(path string) (ValidPathInfo, error) {
+	info := ValidPathInfo{Path: path}
+
+	deriver, err := c.readNixString()
+	if err != nil {
+		return ValidPathInfo{}, fmt.Errorf("reading deriver: %w", err)
+	}
+	info.Deriver = string(deriver)
+
+	narHash, err := c.readNixString()
+	if err != nil {
+		return ValidPathInfo{}, fmt.Errorf("reading NAR hash: %w", err)
+	}
+	info.NarHash = string(narHash)
+
+	references, err := c.readNixStringList()
+	if err != nil {
+		return ValidPathInfo{}, fmt.Errorf("reading references: %w", err)
+	}
+	info.References = references
+
+	registrationTime, err := c.readNixInt()
+	if err != nil {
+		return ValidPathInfo{}, fmt.Errorf("reading registration time: %w", err)
+	}
+	info.RegistrationTime = time.Unix(int64(registrationTime.Value), 0)
+
+	narSize, err := c.readNixInt()
+	if err != nil {
+		return ValidPathInfo{}, fmt.Errorf("reading NAR size: %w", err)
+	}
+	info.NarSize = narSize.Value
+
+	ultimate, err := c.readNixBool()
+	if err != nil {
+		return ValidPathInfo{}, fmt.Errorf("reading ultimate flag: %w", err)
+	}
+	info.Ultimate = bool(ultimate)
+
+	signatures, err := c.readNixStringList()
+	if err != nil {
+		return ValidPathInfo{}, fmt.Errorf("reading signatures: %w", err)
+	}
+	info.Signatures = signatures
+
+	ca, err := c.readNixString()
+	if err != nil {
+		return ValidPathInfo{}, fmt.Errorf("reading content address: %w", err)
+	}
+	info.ContentAddress = string(ca)
+
+	return info, nil
+}
+
+// writeValidPathInfo writes the given path information, including the path, into w.
+func writeValidPathInfo(w io.Writer, info ValidPathInfo) error {
+	var registrationTime uint64
+	if !info.RegistrationTime.IsZero() {
+		registrationTime = uint64(info.RegistrationTime.Unix())
+	}
+
+	if err := writeNixTo(w, pseudo.String(info.Path)); err != nil {
+		return fmt.Errorf("writing path: %w", err)
+	}
+	if err := writeNixTo(w, pseudo.String(info.Deriver)); err != nil {
+		return fmt.Errorf("writing deriver: %w", err)
+	}
+	if err := writeNixTo(w, pseudo.String(info.NarHash)); err != nil {
+		return fmt.Errorf("writing NAR hash: %w", err)
+	}
+	if err := writeNixTo(w, pseudo.StringList(info.References)); err != nil {
+		return fmt.Errorf("writing references: %w", err)
+	}
+	if err := writeNixTo(w, primitive.NewInt(registrationTime)); err != nil {
+		return fmt.Errorf("writing registration time: %w", err)
+	}
+	if err := writeNixTo(w, primitive.NewInt(info.NarSize)); err != nil {
+		return fmt.Errorf("writing NAR size: %w", err)
+	}
+	if err := writeNixTo(w, pseudo.Bool(info.Ultimate)); err != nil {
+		return fmt.Errorf("writing ultimate flag: %w", err)
+	}
+	if err := writeNixTo(w, pseudo.StringList(info.Signatures)); err != nil {
+		return fmt.Errorf("writing signatures: %w", err)
+	}
+	if err := writeNixTo(w, pseudo.String(info.ContentAddress)); err != nil {
+		return fmt.Errorf("writing content address: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/msanft/proton/internal/protocol/opcode"
+	"github.com/msanft/proton/internal/pseudo"
+)
+
+// VerifyStore checks the consistency of the daemon's store and returns whether
+// any errors were found. The progress and the errors found are written into the
+// connection's stderr writer.
+// If checkContents is set, the contents of all paths are checked against their
+// hashes. If repair is set, errors are repaired where possible.
+func (c *Conn) VerifyStore(checkContents bool, repair bool) (bool, error) {
+	if err := c.writeNix(opcode.VerifyStore); err != nil {
+		return false, fmt.Errorf("write operation to connection: %w", err)
+	}
+	if err := c.writeNix(pseudo.Bool(checkContents)); err != nil {
+		return false, fmt.Errorf("write check-contents flag to connection: %w", err)
+	}
+	if err := c.writeNix(pseudo.Bool(repair)); err != nil {
+		return false, fmt.Errorf("write repair flag to connection: %w", err)
+	}
+
+	if err := c.ParseStderr(); err != nil {
+		return false, fmt.Errorf("receive stderr: %w", err)
+	}
+
+	errorsFound, err := c.readNixBool()
+	if err != nil {
+		return false, fmt.Errorf("read errors found: %w", err)
+	}
+
+	return bool(errorsFound), nil
+}
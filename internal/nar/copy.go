@@ -0,0 +1,207 @@
+package nar
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Copy copies exactly one NAR from src to dst, without buffering it.
+// As NARs aren't length-prefixed, the archive is parsed to know where it ends,
+// and nothing after its end is read from src.
+//
+// If writing to dst fails, the rest of the NAR is still consumed from src, so that
+// src is positioned after the archive, and the write error is returned.
+// It returns the number of bytes copied.
+func Copy(dst io.Writer, src io.Reader) (int64, error) {
+	w := &stickyWriter{w: dst}
+	p := &parser{r: io.TeeReader(src, w)}
+
+	if err := p.expect(magic); err != nil {
+		return w.n, fmt.Errorf("reading magic: %w", err)
+	}
+	if err := p.node(); err != nil {
+		return w.n, err
+	}
+	return w.n, w.err
+}
+
+// parser reads the structure of a NAR.
+type parser struct {
+	r io.Reader
+}
+
+// node reads a file system object, including the surrounding parentheses.
+func (p *parser) node() error {
+	if err := p.expect("("); err != nil {
+		return err
+	}
+	if err := p.expect("type"); err != nil {
+		return err
+	}
+	typ, err := p.readToken()
+	if err != nil {
+		return fmt.Errorf("reading type: %w", err)
+	}
+
+	switch typ {
+	case "regular":
+		tok, err := p.readToken()
+		if err != nil {
+			return err
+		}
+		if tok == "executable" {
+			if err := p.expect(""); err != nil {
+				return err
+			}
+			if tok, err = p.readToken(); err != nil {
+				return err
+			}
+		}
+		if tok != "contents" {
+			return fmt.Errorf("unexpected token %q, want %q", tok, "contents")
+		}
+		if err := p.skipContents(); err != nil {
+			return fmt.Errorf("reading contents: %w", err)
+		}
+		return p.expect(")")
+	case "symlink":
+		if err := p.expect("target"); err != nil {
+			return err
+		}
+		if _, err := p.readToken(); err != nil {
+			return fmt.Errorf("reading symlink target: %w", err)
+		}
+		return p.expect(")")
+	case "directory":
+		for {
+			tok, err := p.readToken()
+			if err != nil {
+				return err
+			}
+			switch tok {
+			case ")":
+				return nil
+			case "entry":
+				if err := p.entry(); err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("unexpected token %q in directory", tok)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown type %q", typ)
+	}
+}
+
+// entry reads a directory entry, after the "entry" token.
+func (p *parser) entry() error {
+	if err := p.expect("("); err != nil {
+		return err
+	}
+	if err := p.expect("name"); err != nil {
+		return err
+	}
+	name, err := p.readToken()
+	if err != nil {
+		return fmt.Errorf("reading entry name: %w", err)
+	}
+	if err := p.expect("node"); err != nil {
+		return err
+	}
+	if err := p.node(); err != nil {
+		return fmt.Errorf("entry %q: %w", name, err)
+	}
+	return p.expect(")")
+}
+
+// expect reads a string and checks that it equals want.
+func (p *parser) expect(want string) error {
+	got, err := p.readToken()
+	if err != nil {
+		return err
+	}
+	if got != want {
+		return fmt.Errorf("unexpected token %q, want %q", got, want)
+	}
+	return nil
+}
+
+// readToken reads a string that isn't file contents.
+func (p *parser) readToken() (string, error) {
+	n, err := p.readInt()
+	if err != nil {
+		return "", err
+	}
+	if n > maxTokenLen {
+		return "", fmt.Errorf("string of length %d exceeds maximum of %d", n, maxTokenLen)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(p.r, buf); err != nil {
+		return "", err
+	}
+	if err := p.skipPadding(n); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// skipContents reads file contents without holding them in memory.
+func (p *parser) skipContents() error {
+	n, err := p.readInt()
+	if err != nil {
+		return err
+	}
+	if _, err := io.CopyN(io.Discard, p.r, int64(n)); err != nil {
+		return err
+	}
+	return p.skipPadding(n)
+}
+
+// readInt reads a little-endian 64-bit integer.
+func (p *parser) readInt() (uint64, error) {
+	var buf [8]byte
+	if _, err := io.ReadFull(p.r, buf[:]); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(buf[:]), nil
+}
+
+// skipPadding reads the zero bytes padding a string of length n to a multiple of 8.
+func (p *parser) skipPadding(n uint64) error {
+	var buf [8]byte
+	pad := buf[:(8-n%8)%8]
+	if _, err := io.ReadFull(p.r, pad); err != nil {
+		return err
+	}
+	for _, b := range pad {
+		if b != 0 {
+			return errors.New("non-zero padding")
+		}
+	}
+	return nil
+}
+
+// stickyWriter counts the bytes written to w. After the first error,
+// it discards all writes and keeps reporting success, so that reading
+// through an io.TeeReader can continue.
+type stickyWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+// Write writes p to the underlying writer, unless an error occurred before.
+func (s *stickyWriter) Write(p []byte) (int, error) {
+	if s.err != nil {
+		return len(p), nil
+	}
+	n, err := s.w.Write(p)
+	s.n += int64(n)
+	if err != nil {
+		s.err = err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,111 @@
+package nar
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// narString encodes the given strings like they appear in a NAR.
+func narString(strs ...string) []byte {
+	var buf []byte
+	for _, s := range strs {
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(len(s)))
+		buf = append(buf, s...)
+		buf = append(buf, make([]byte, (8-len(s)%8)%8)...)
+	}
+	return buf
+}
+
+// testNAR is a directory containing an executable, a symlink and a subdirectory.
+var testNAR = narString(
+	"nix-archive-1", "(", "type", "directory",
+	"entry", "(", "name", "bin", "node",
+	"(", "type", "directory",
+	"entry", "(", "name", "hello", "node",
+	"(", "type", "regular", "executable", "", "contents", "#!/bin/sh\necho hello\n", ")",
+	")",
+	")",
+	")",
+	"entry", "(", "name", "link", "node",
+	"(", "type", "symlink", "target", "bin/hello", ")",
+	")",
+	"entry", "(", "name", "readme", "node",
+	"(", "type", "regular", "contents", "", ")",
+	")",
+	")",
+)
+
+func TestCopy(t *testing.T) {
+	t.Run("directory", func(t *testing.T) {
+		require := require.New(t)
+		assert := assert.New(t)
+
+		src := bytes.NewReader(append(append([]byte{}, testNAR...), "trailing"...))
+		var dst bytes.Buffer
+
+		n, err := Copy(&dst, src)
+		require.NoError(err)
+		assert.Equal(int64(len(testNAR)), n)
+		assert.Equal(testNAR, dst.Bytes())
+
+		rest, err := io.ReadAll(src)
+		require.NoError(err)
+		assert.Equal("trailing", string(rest))
+	})
+
+	t.Run("regular file", func(t *testing.T) {
+		require := require.New(t)
+		assert := assert.New(t)
+
+		nar := narString("nix-archive-1", "(", "type", "regular", "contents", "12345678", ")")
+		var dst bytes.Buffer
+
+		_, err := Copy(&dst, bytes.NewReader(nar))
+		require.NoError(err)
+		assert.Equal(nar, dst.Bytes())
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		_, err := Copy(io.Discard, bytes.NewReader(testNAR[:len(testNAR)-8]))
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid magic", func(t *testing.T) {
+		_, err := Copy(io.Discard, bytes.NewReader(narString("nix-archive-2", "(", "type", "symlink", "target", "x", ")")))
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		_, err := Copy(io.Discard, bytes.NewReader(narString("nix-archive-1", "(", "type", "fifo", ")")))
+		assert.Error(t, err)
+	})
+
+	t.Run("write error consumes archive", func(t *testing.T) {
+		require := require.New(t)
+		assert := assert.New(t)
+
+		src := bytes.NewReader(append(append([]byte{}, testNAR...), "trailing"...))
+		writeErr := errors.New("disk full")
+
+		_, err := Copy(failingWriter{writeErr}, src)
+		require.ErrorIs(err, writeErr)
+
+		rest, err := io.ReadAll(src)
+		require.NoError(err)
+		assert.Equal("trailing", string(rest))
+	})
+}
+
+type failingWriter struct {
+	err error
+}
+
+func (w failingWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
@@ -0,0 +1,137 @@
+package nar
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Dump writes the NAR serialization of the file system object at path into w.
+// Regular files, directories and symlinks are supported. Regular files are
+// executable in the archive if they are executable by their owner.
+func Dump(w io.Writer, path string) error {
+	d := &dumper{w: w}
+	if err := d.writeString(magic); err != nil {
+		return err
+	}
+	return d.node(path)
+}
+
+// dumper writes the structure of a NAR.
+type dumper struct {
+	w io.Writer
+}
+
+// node writes the file system object at path, including the surrounding parentheses.
+func (d *dumper) node(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+
+	if err := d.writeString("(", "type"); err != nil {
+		return err
+	}
+
+	switch mode := info.Mode(); {
+	case mode.IsRegular():
+		if err := d.writeString("regular"); err != nil {
+			return err
+		}
+		if mode&0o100 != 0 {
+			if err := d.writeString("executable", ""); err != nil {
+				return err
+			}
+		}
+		if err := d.writeString("contents"); err != nil {
+			return err
+		}
+		if err := d.writeContents(path, info.Size()); err != nil {
+			return fmt.Errorf("writing contents of %s: %w", path, err)
+		}
+	case mode&fs.ModeSymlink != 0:
+		target, err := os.Readlink(path)
+		if err != nil {
+			return err
+		}
+		if err := d.writeString("symlink", "target", target); err != nil {
+			return err
+		}
+	case mode.IsDir():
+		if err := d.writeString("directory"); err != nil {
+			return err
+		}
+		// Entries are sorted by name, as the archive needs to be canonical.
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := d.writeString("entry", "(", "name", entry.Name(), "node"); err != nil {
+				return err
+			}
+			if err := d.node(filepath.Join(path, entry.Name())); err != nil {
+				return err
+			}
+			if err := d.writeString(")"); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported file type %s of %s", mode.Type(), path)
+	}
+
+	return d.writeString(")")
+}
+
+// writeContents writes the contents of the regular file at path,
+// which is expected to be of the given size.
+func (d *dumper) writeContents(path string, size int64) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := d.writeInt(uint64(size)); err != nil {
+		return err
+	}
+	if _, err := io.CopyN(d.w, f, size); err != nil {
+		return fmt.Errorf("file changed while reading it: %w", err)
+	}
+	return d.writePadding(uint64(size))
+}
+
+// writeString writes the given strings.
+func (d *dumper) writeString(strs ...string) error {
+	for _, s := range strs {
+		if err := d.writeInt(uint64(len(s))); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(d.w, s); err != nil {
+			return err
+		}
+		if err := d.writePadding(uint64(len(s))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeInt writes a little-endian 64-bit integer.
+func (d *dumper) writeInt(i uint64) error {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], i)
+	_, err := d.w.Write(buf[:])
+	return err
+}
+
+// writePadding writes the zero bytes padding a string of length n to a multiple of 8.
+func (d *dumper) writePadding(n uint64) error {
+	var buf [8]byte
+	_, err := d.w.Write(buf[:(8-n%8)%8])
+	return err
+}
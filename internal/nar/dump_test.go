@@ -0,0 +1,45 @@
+package nar
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDump(t *testing.T) {
+	t.Run("directory", func(t *testing.T) {
+		require := require.New(t)
+		assert := assert.New(t)
+
+		dir := t.TempDir()
+		require.NoError(os.Mkdir(filepath.Join(dir, "bin"), 0o755))
+		require.NoError(os.WriteFile(filepath.Join(dir, "bin", "hello"), []byte("#!/bin/sh\necho hello\n"), 0o755))
+		require.NoError(os.Symlink("bin/hello", filepath.Join(dir, "link")))
+		require.NoError(os.WriteFile(filepath.Join(dir, "readme"), nil, 0o644))
+
+		var buf bytes.Buffer
+		require.NoError(Dump(&buf, dir))
+		assert.Equal(testNAR, buf.Bytes())
+	})
+
+	t.Run("regular file", func(t *testing.T) {
+		require := require.New(t)
+		assert := assert.New(t)
+
+		file := filepath.Join(t.TempDir(), "file")
+		require.NoError(os.WriteFile(file, []byte("12345678"), 0o644))
+
+		var buf bytes.Buffer
+		require.NoError(Dump(&buf, file))
+		assert.Equal(narString("nix-archive-1", "(", "type", "regular", "contents", "12345678", ")"), buf.Bytes())
+	})
+
+	t.Run("not existing", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.Error(t, Dump(&buf, filepath.Join(t.TempDir(), "missing")))
+	})
+}
@@ -0,0 +1,12 @@
+// The nar package implements the Nix Archive (NAR) format, the serialization
+// of file system objects used by Nix.
+//
+// See https://nix.dev/manual/nix/2.24/protocols/nix-archive.
+package nar
+
+// magic is the string every NAR starts with.
+const magic = "nix-archive-1"
+
+// maxTokenLen limits the length of strings in the archive that aren't file contents,
+// like names and symlink targets.
+const maxTokenLen = 1 << 16
@@ -1,5 +1,7 @@
 package buildmode
 
+import "github.com/msanft/proton/internal/primitive"
+
 type BuildMode uint64
 
 const (
@@ -7,3 +9,23 @@ const (
 	Repair
 	Check
 )
+
+// MarshalNix serializes a build mode to the Nix wire format.
+func (m BuildMode) MarshalNix() ([]byte, error) {
+	return primitive.NewInt(uint64(m)).MarshalNix()
+}
+
+// UnmarshalNix deserializes a build mode from the Nix wire format.
+func (m *BuildMode) UnmarshalNix(raw []byte) error {
+	var i primitive.Int
+	if err := i.UnmarshalNix(raw); err != nil {
+		return err
+	}
+	*m = BuildMode(i.Value)
+	return nil
+}
+
+// Size returns the size of the build mode in bytes.
+func (m BuildMode) Size() uint64 {
+	return primitive.NewInt(uint64(m)).Size()
+}
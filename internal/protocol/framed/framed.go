@@ -0,0 +1,53 @@
+// The framed package implements the framed stream encoding, which the Nix daemon
+// protocol uses to send data of unknown length, like NARs.
+//
+// A framed stream consists of frames, each being a length followed by as many
+// bytes of data, without padding. A frame of length zero ends the stream.
+package framed
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/msanft/proton/internal/primitive"
+)
+
+// Writer writes a framed stream. Every call to Write produces one frame.
+// Close must be called to end the stream.
+type Writer struct {
+	w io.Writer
+}
+
+// NewWriter creates a new framed stream writer writing into w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Write writes p as a single frame. Empty writes produce no frame,
+// as they would end the stream.
+func (f *Writer) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	l, err := primitive.NewInt(uint64(len(p))).MarshalNix()
+	if err != nil {
+		return 0, fmt.Errorf("marshaling frame length: %w", err)
+	}
+	if _, err := f.w.Write(l); err != nil {
+		return 0, fmt.Errorf("writing frame length: %w", err)
+	}
+	return f.w.Write(p)
+}
+
+// Close ends the stream by writing an empty frame.
+// It doesn't close the underlying writer.
+func (f *Writer) Close() error {
+	l, err := primitive.NewInt(0).MarshalNix()
+	if err != nil {
+		return fmt.Errorf("marshaling frame length: %w", err)
+	}
+	if _, err := f.w.Write(l); err != nil {
+		return fmt.Errorf("writing end of stream: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package framed
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	n, err := w.Write([]byte("foo"))
+	require.NoError(err)
+	assert.Equal(3, n)
+
+	n, err = w.Write(nil)
+	require.NoError(err)
+	assert.Equal(0, n)
+
+	n, err = w.Write([]byte("barbazqux"))
+	require.NoError(err)
+	assert.Equal(9, n)
+
+	require.NoError(w.Close())
+
+	assert.Equal([]byte{
+		3, 0, 0, 0, 0, 0, 0, 0, 0x66, 0x6f, 0x6f,
+		9, 0, 0, 0, 0, 0, 0, 0, 0x62, 0x61, 0x72, 0x62, 0x61, 0x7a, 0x71, 0x75, 0x78,
+		0, 0, 0, 0, 0, 0, 0, 0,
+	}, buf.Bytes())
+}
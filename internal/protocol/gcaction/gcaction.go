@@ -1,5 +1,7 @@
 package gcaction
 
+import "github.com/msanft/proton/internal/primitive"
+
 type GCAction uint64
 
 const (
@@ -8,3 +10,23 @@ const (
 	DeleteDead
 	DeleteSpecific
 )
+
+// MarshalNix serializes a garbage collector action to the Nix wire format.
+func (a GCAction) MarshalNix() ([]byte, error) {
+	return primitive.NewInt(uint64(a)).MarshalNix()
+}
+
+// UnmarshalNix deserializes a garbage collector action from the Nix wire format.
+func (a *GCAction) UnmarshalNix(raw []byte) error {
+	var i primitive.Int
+	if err := i.UnmarshalNix(raw); err != nil {
+		return err
+	}
+	*a = GCAction(i.Value)
+	return nil
+}
+
+// Size returns the size of the garbage collector action in bytes.
+func (a GCAction) Size() uint64 {
+	return primitive.NewInt(uint64(a)).Size()
+}
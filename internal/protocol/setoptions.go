@@ -2,6 +2,7 @@ package protocol
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/msanft/proton/internal/primitive"
 	"github.com/msanft/proton/internal/protocol/verbosity"
@@ -101,13 +102,27 @@ func (o SetOptions) MarshalNix() ([]byte, error) {
 	}
 	buf = append(buf, useSubstitutes...)
 
-	for k, v := range o.Options {
+	numOptions, err := primitive.NewInt(uint64(len(o.Options))).MarshalNix()
+	if err != nil {
+		return nil, fmt.Errorf("marshaling number of options: %w", err)
+	}
+	buf = append(buf, numOptions...)
+
+	// Sort the keys so that the encoding is deterministic.
+	keys := make([]pseudo.String, 0, len(o.Options))
+	for k := range o.Options {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	for _, k := range keys {
 		key, err := k.MarshalNix()
 		if err != nil {
 			return nil, fmt.Errorf("marshaling key %s: %w", k, err)
 		}
 		buf = append(buf, key...)
 
+		v := o.Options[k]
 		value, err := v.MarshalNix()
 		if err != nil {
 			return nil, fmt.Errorf("marshaling value %s: %w", v, err)
@@ -143,7 +158,6 @@ func (o *SetOptions) UnmarshalNix(raw []byte) error {
 	raw = raw[newOpts.Verbosity.Size():]
 
 	if err := newOpts.MaxBuildJobs.UnmarshalNix(raw); err != nil {
-
 		return fmt.Errorf("unmarshaling maxBuildJobs: %w", err)
 	}
 	raw = raw[newOpts.MaxBuildJobs.Size():]
@@ -183,9 +197,15 @@ func (o *SetOptions) UnmarshalNix(raw []byte) error {
 	}
 	raw = raw[newOpts.UseSubstitutes.Size():]
 
+	var numOptions primitive.Int
+	if err := numOptions.UnmarshalNix(raw); err != nil {
+		return fmt.Errorf("unmarshaling number of options: %w", err)
+	}
+	raw = raw[numOptions.Size():]
+
 	newOpts.Options = make(map[pseudo.String]pseudo.String)
 
-	for len(raw) > 0 {
+	for i := uint64(0); i < numOptions.Value; i++ {
 		var key pseudo.String
 		if err := key.UnmarshalNix(raw); err != nil {
 			return fmt.Errorf("unmarshaling key: %w", err)
@@ -218,7 +238,8 @@ func (o *SetOptions) Size() uint64 {
 		o.logType.Size() +
 		o.printBuildTrace.Size() +
 		o.BuildCores.Size() +
-		o.UseSubstitutes.Size()
+		o.UseSubstitutes.Size() +
+		primitive.NewInt(uint64(len(o.Options))).Size()
 
 	for k, v := range o.Options {
 		size += k.Size() + v.Size()
@@ -0,0 +1,65 @@
+package protocol
+
+import (
+	"testing"
+
+	"github.com/msanft/proton/internal/primitive"
+	"github.com/msanft/proton/internal/pseudo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetOptionsMarshal(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	o := SetOptions{
+		MaxBuildJobs: primitive.NewInt(4),
+		Options: map[pseudo.String]pseudo.String{
+			"sandbox": "true",
+			"cores":   "2",
+		},
+	}
+	raw, err := o.MarshalNix()
+	require.NoError(err)
+
+	header := make([]byte, 12*8)
+	header[4*8] = 4 // MaxBuildJobs
+	assert.Equal(append(header,
+		2, 0, 0, 0, 0, 0, 0, 0,
+		5, 0, 0, 0, 0, 0, 0, 0, 'c', 'o', 'r', 'e', 's', 0, 0, 0,
+		1, 0, 0, 0, 0, 0, 0, 0, '2', 0, 0, 0, 0, 0, 0, 0,
+		7, 0, 0, 0, 0, 0, 0, 0, 's', 'a', 'n', 'd', 'b', 'o', 'x', 0,
+		4, 0, 0, 0, 0, 0, 0, 0, 't', 'r', 'u', 'e', 0, 0, 0, 0,
+	), raw)
+	assert.Equal(uint64(len(raw)), o.Size())
+}
+
+func TestSetOptionsUnmarshal(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	o := SetOptions{
+		KeepGoing:      true,
+		BuildCores:     primitive.NewInt(8),
+		UseSubstitutes: true,
+		Options: map[pseudo.String]pseudo.String{
+			"substituters": "https://cache.nixos.org",
+		},
+	}
+	raw, err := o.MarshalNix()
+	require.NoError(err)
+
+	var got SetOptions
+	require.NoError(got.UnmarshalNix(raw))
+	assert.Equal(o, got)
+
+	t.Run("no options", func(t *testing.T) {
+		raw, err := SetOptions{}.MarshalNix()
+		require.NoError(err)
+
+		var got SetOptions
+		require.NoError(got.UnmarshalNix(raw))
+		assert.Empty(got.Options)
+	})
+}
@@ -9,6 +9,7 @@ type Marker uint64
 
 const (
 	MarkerWrite         Marker = 0x64617416
+	MarkerRead          Marker = 0x64617461
 	MarkerError         Marker = 0x63787470
 	MarkerNext          Marker = 0x6f6c6d67
 	MarkerStartActivity Marker = 0x53545254
@@ -0,0 +1,15 @@
+package stderr
+
+// ResultKind is the kind of a result reported for an activity.
+type ResultKind uint64
+
+const (
+	ResultFileLinked       ResultKind = 100
+	ResultBuildLogLine     ResultKind = 101
+	ResultUntrustedPath    ResultKind = 102
+	ResultCorruptedPath    ResultKind = 103
+	ResultSetPhase         ResultKind = 104
+	ResultProgress         ResultKind = 105
+	ResultSetExpected      ResultKind = 106
+	ResultPostBuildLogLine ResultKind = 107
+)
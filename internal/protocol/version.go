@@ -11,6 +11,9 @@ const (
 	Major = 1
 	// Minor is the minor version number of the Nix daemon protocol supported by this implementation.
 	Minor = 37
+	// MinMinor is the oldest minor version number of the Nix daemon protocol this implementation
+	// can fall back to. It corresponds to Nix 2.3.
+	MinMinor = 21
 )
 
 // Version is a version of the Nix daemon protocol.
@@ -29,6 +32,16 @@ func OwnVersion() Version {
 	return NewVersion(Major, Minor)
 }
 
+// Major returns the major version number.
+func (v Version) Major() uint8 {
+	return v.major
+}
+
+// Minor returns the minor version number.
+func (v Version) Minor() uint8 {
+	return v.minor
+}
+
 // MarshalNix serializes the protocol version to the Nix wire format.
 func (v Version) MarshalNix() ([]byte, error) {
 	i := uint64(v.major)<<8 | uint64(v.minor)
@@ -16,3 +16,15 @@ func TestVersionMarshal(t *testing.T) {
 	require.NoError(err)
 	assert.Equal([]byte{34, 1, 0, 0, 0, 0, 0, 0}, raw)
 }
+
+func TestVersionUnmarshal(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	var v Version
+	err := v.UnmarshalNix([]byte{21, 1, 0, 0, 0, 0, 0, 0})
+	require.NoError(err)
+	assert.Equal(uint8(1), v.Major())
+	assert.Equal(uint8(21), v.Minor())
+	assert.Equal("v1.21", v.String())
+}
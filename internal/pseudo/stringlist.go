@@ -0,0 +1,59 @@
+package pseudo
+
+import (
+	"fmt"
+
+	"github.com/msanft/proton/internal/primitive"
+)
+
+// StringList is a pseudo-type for a list (or set) of strings used by Nix.
+// Under the hood, it is an integer primitive holding the number of elements,
+// followed by the elements as pseudo-strings.
+type StringList []string
+
+// MarshalNix serializes the pseudo-string-list to the Nix wire format.
+func (l StringList) MarshalNix() ([]byte, error) {
+	buf, err := primitive.NewInt(uint64(len(l))).MarshalNix()
+	if err != nil {
+		return nil, fmt.Errorf("marshaling list length: %w", err)
+	}
+	for _, s := range l {
+		elem, err := String(s).MarshalNix()
+		if err != nil {
+			return nil, fmt.Errorf("marshaling element %q: %w", s, err)
+		}
+		buf = append(buf, elem...)
+	}
+	return buf, nil
+}
+
+// UnmarshalNix deserializes the pseudo-string-list from the Nix wire format.
+func (l *StringList) UnmarshalNix(raw []byte) error {
+	var length primitive.Int
+	if err := length.UnmarshalNix(raw); err != nil {
+		return fmt.Errorf("unmarshaling list length: %w", err)
+	}
+	raw = raw[length.Size():]
+
+	var newList StringList
+	for i := uint64(0); i < length.Value; i++ {
+		var s String
+		if err := s.UnmarshalNix(raw); err != nil {
+			return fmt.Errorf("unmarshaling element %d: %w", i, err)
+		}
+		raw = raw[s.Size():]
+		newList = append(newList, string(s))
+	}
+
+	*l = newList
+	return nil
+}
+
+// Size returns the size of the pseudo-string-list in bytes.
+func (l StringList) Size() uint64 {
+	size := primitive.NewInt(uint64(len(l))).Size()
+	for _, s := range l {
+		size += String(s).Size()
+	}
+	return size
+}
@@ -0,0 +1,44 @@
+package pseudo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringListMarshal(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	t.Run("empty", func(t *testing.T) {
+		raw, err := StringList{}.MarshalNix()
+		require.NoError(err)
+		assert.Equal([]byte{0, 0, 0, 0, 0, 0, 0, 0}, raw)
+	})
+
+	t.Run("two elements", func(t *testing.T) {
+		raw, err := StringList{"foo", "barbazqu"}.MarshalNix()
+		require.NoError(err)
+		assert.Equal([]byte{
+			2, 0, 0, 0, 0, 0, 0, 0,
+			3, 0, 0, 0, 0, 0, 0, 0, 0x66, 0x6f, 0x6f, 0, 0, 0, 0, 0,
+			8, 0, 0, 0, 0, 0, 0, 0, 0x62, 0x61, 0x72, 0x62, 0x61, 0x7a, 0x71, 0x75,
+		}, raw)
+	})
+}
+
+func TestStringListUnmarshal(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	var l StringList
+	err := l.UnmarshalNix([]byte{
+		2, 0, 0, 0, 0, 0, 0, 0,
+		3, 0, 0, 0, 0, 0, 0, 0, 0x66, 0x6f, 0x6f, 0, 0, 0, 0, 0,
+		8, 0, 0, 0, 0, 0, 0, 0, 0x62, 0x61, 0x72, 0x62, 0x61, 0x7a, 0x71, 0x75,
+	})
+	require.NoError(err)
+	assert.Equal(StringList{"foo", "barbazqu"}, l)
+	assert.Equal(uint64(40), l.Size())
+}